@@ -0,0 +1,148 @@
+package gocherry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	rpprof "runtime/pprof"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pkg/profile"
+
+	"github.com/vishenosik/gocherry/pkg/config"
+	_http "github.com/vishenosik/gocherry/pkg/http"
+	"github.com/vishenosik/gocherry/pkg/logs"
+)
+
+const (
+	AdminBuildInfoRoute  = "/buildinfo"
+	AdminMetricsRoute    = "/metrics"
+	AdminConfigRoute     = "/config"
+	AdminServicesRoute   = "/services"
+	AdminRoutesRoute     = "/routes"
+	AdminGoroutinesRoute = "/debug/goroutines"
+	AdminPprofRoute      = "/debug/pprof"
+)
+
+type AdminConfigEnv struct {
+	Host string `env:"ADMIN_HOST" env-default:"localhost" desc:"Admin server host"`
+	Port uint16 `env:"ADMIN_PORT" env-default:"8081" desc:"Admin server port" validate:"gte=1"`
+}
+
+func (AdminConfigEnv) Desc() string {
+	return "admin server settings"
+}
+
+// WithAdminServer starts an admin listener next to the public one.
+// It serves pprof, goroutine dumps, build info and the build_info metric, effective config with sources
+// and lists of services and http routes.
+func WithAdminServer() AppOption {
+	return func(app *App) {
+
+		app.addConfigs(AdminConfigEnv{})
+
+		var envConf AdminConfigEnv
+		if err := config.ReadConfigEnv(&envConf); err != nil {
+			app.Log.Warn("init admin server: failed to read config", logs.Error(err))
+		}
+
+		server, err := _http.NewHttpServer(
+			app.adminRoutes(),
+			_http.WithServerConfig(config.Server{
+				Host: envConf.Host,
+				Port: envConf.Port,
+			}),
+			_http.WithListenFunc(app.listen),
+		)
+		if err != nil {
+			app.Log.Warn("failed to add admin server", logs.Error(err))
+			return
+		}
+
+		app.AddService(server, Named("admin"))
+	}
+}
+
+// WithProfile runs github.com/pkg/profile profiling from the app start until it stops,
+// e.g. WithProfile(profile.CPUProfile, profile.ProfilePath(".")).
+func WithProfile(options ...func(*profile.Profile)) AppOption {
+	return func(app *App) {
+		app.profileOptions = append(slices.Clone(options), profile.Quiet, profile.NoShutdownHook)
+	}
+}
+
+func (app *App) adminRoutes() http.Handler {
+	router := chi.NewRouter()
+
+	router.Get(AdminBuildInfoRoute, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		BuildInfoJson(w)
+	})
+
+	router.Get(AdminMetricsRoute, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		BuildInfoMetric(w)
+	})
+
+	router.Get(AdminConfigRoute, func(w http.ResponseWriter, r *http.Request) {
+		values, err := config.EffectiveValues(slices.Concat(app.configs.Structs(), config.Structs())...)
+		if err != nil {
+			_http.SendErrors(w, http.StatusInternalServerError, _http.NewError(http.StatusInternalServerError, err))
+			return
+		}
+		writeJson(w, values)
+	})
+
+	router.Get(AdminServicesRoute, func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, app.Services())
+	})
+
+	router.Get(AdminRoutesRoute, func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, app.Routes())
+	})
+
+	router.Get(AdminGoroutinesRoute, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+
+	router.Route(AdminPprofRoute, func(r chi.Router) {
+		r.HandleFunc("/", pprof.Index)
+		r.HandleFunc("/cmdline", pprof.Cmdline)
+		r.HandleFunc("/profile", pprof.Profile)
+		r.HandleFunc("/symbol", pprof.Symbol)
+		r.HandleFunc("/trace", pprof.Trace)
+		r.HandleFunc("/{profile}", pprof.Index)
+	})
+
+	app.health.Routers(router)
+
+	return router
+}
+
+func writeJson(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+// startProfile starts profiling if it's configured by WithProfile.
+func (app *App) startProfile() {
+	if len(app.profileOptions) == 0 {
+		return
+	}
+	if app.profile != nil {
+		return
+	}
+	app.profile = profile.Start(app.profileOptions...)
+}
+
+func (app *App) stopProfile() {
+	if app.profile == nil {
+		return
+	}
+	app.profile.Stop()
+	app.profile = nil
+}
@@ -0,0 +1,80 @@
+package gocherry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vishenosik/gocherry/pkg/config"
+)
+
+type adminTestConfig struct {
+	User     string `env:"ADMIN_TEST_USER" env-default:"user" desc:"User"`
+	Password string `env:"ADMIN_TEST_PASSWORD" env-default:"password" desc:"Password"`
+}
+
+func TestAdminRoutes(_t *testing.T) {
+
+	t := &T{_t}
+
+	newRoutes := func(t *testing.T, opts ...AppOption) http.Handler {
+		app, err := NewApp(append([]AppOption{
+			WithService(&testService{name: "service", journal: new(journal)}, Named("service")),
+		}, opts...)...)
+		require.NoError(t, err)
+		return app.adminRoutes()
+	}
+
+	get := func(handler http.Handler, route string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, route, nil))
+		return w
+	}
+
+	t.Run("services", func(t *testing.T) {
+		w := get(newRoutes(t), AdminServicesRoute)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var services []ServiceInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &services))
+		require.Equal(t, []ServiceInfo{{Name: "service", Type: "service", State: StateRegistered}}, services)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		t.Setenv("ADMIN_TEST_USER", "admin")
+		t.Setenv("ENV", "test")
+
+		w := get(newRoutes(t, WithConfigs(adminTestConfig{})), AdminConfigRoute)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var values []config.Value
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &values))
+		require.Equal(t, []config.Value{
+			{Section: "logs.EnvConfig", Env: "ENV", Value: "test", Source: config.SourceEnv},
+			{Section: "logs.EnvConfig", Env: "LOG_LEVEL", Value: ""},
+			{Section: "gocherry.adminTestConfig", Env: "ADMIN_TEST_USER", Value: "admin", Source: config.SourceEnv},
+			{Section: "gocherry.adminTestConfig", Env: "ADMIN_TEST_PASSWORD", Value: config.Redacted, Source: config.SourceDefault},
+		}, values)
+	})
+
+	t.Run("build info", func(t *testing.T) {
+		w := get(newRoutes(t), AdminBuildInfoRoute)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "git_commit")
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		w := get(newRoutes(t), AdminMetricsRoute)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "# TYPE build_info gauge\nbuild_info{")
+	})
+
+	t.Run("pprof", func(t *testing.T) {
+		handler := newRoutes(t)
+		require.Equal(t, http.StatusOK, get(handler, AdminPprofRoute+"/").Code)
+		require.Equal(t, http.StatusOK, get(handler, AdminPprofRoute+"/heap").Code)
+		require.Equal(t, http.StatusOK, get(handler, AdminGoroutinesRoute).Code)
+	})
+}
@@ -2,12 +2,27 @@ package gocherry
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
 	"sync"
+	"syscall"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/pkg/profile"
+
+	"github.com/vishenosik/gocherry/pkg/config"
 	"github.com/vishenosik/gocherry/pkg/errors"
+	_grpc "github.com/vishenosik/gocherry/pkg/grpc"
+	"github.com/vishenosik/gocherry/pkg/handoff"
+	"github.com/vishenosik/gocherry/pkg/health"
+	_http "github.com/vishenosik/gocherry/pkg/http"
 	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/systemd"
 
 	_ctx "github.com/vishenosik/gocherry/pkg/context"
 )
@@ -21,12 +36,63 @@ type Closer interface {
 	Close(ctx context.Context) error
 }
 
+const (
+	defaultStopTimeout = 15 * time.Second
+)
+
+// Exit codes returned by ExitCode.
+const (
+	ExitCodeOK      = 0
+	ExitCodeFailure = 1
+)
+
 type App struct {
-	Log      *slog.Logger
-	services []Service
-	closers  []Closer
+	Log        *slog.Logger
+	components []*component
+
+	// failures receives errors of services failed after start
+	failures chan error
+	// stopping is closed once the app begins to stop
+	stopping    chan struct{}
+	stopTimeout time.Duration
+	// drainDelay is the time between marking the app not ready and stopping services
+	drainDelay         time.Duration
+	serviceStopTimeout time.Duration
+
+	health    *health.Registry
+	ready     chan struct{}
+	readyOnce sync.Once
 
-	once sync.Once
+	reloader *config.Reloader
+	// handoff passes listeners between processes, it's nil unless WithListenerHandoff is set
+	handoff *handoff.Handoff
+	// notifier reports the app state to systemd, it's nil unless WithSystemdNotify is set
+	notifier *systemd.Notifier
+	// configWatch is the interval App.Run checks the env file for changes with
+	configWatch time.Duration
+	// skipPreflight disables the config check of NewApp
+	skipPreflight bool
+
+	hooks hooks
+
+	// configs lists config structs of the app components
+	configs    *config.Registry
+	provided   []any
+	httpRoutes []httpRoute
+	// httpRouter serves http routes, it's built by NewApp
+	httpRouter *chi.Mux
+	// panicHooks report panics recovered by the app servers
+	panicHooks   []errors.PanicHook
+	grpcServices _grpc.GrpcServices
+	httpOptions  []_http.ServerOption
+	grpcOptions  []_grpc.ServerOption
+	// initErrs collects errors of options
+	initErrs *errors.MultiError
+
+	profileOptions []func(*profile.Profile)
+	profile        interface{ Stop() }
+
+	mu sync.Mutex
 }
 
 type AppOption = func(*App)
@@ -34,35 +100,59 @@ type AppOption = func(*App)
 func NewApp(opts ...AppOption) (*App, error) {
 
 	log := logs.SetupLogger()
+	configs := config.NewRegistry(logs.EnvConfig{})
 
 	app := &App{
-		Log: log,
+		Log:                log,
+		stopTimeout:        defaultStopTimeout,
+		serviceStopTimeout: defaultServiceStopTimeout,
+		health:             health.NewRegistry(),
+		reloader:           config.NewReloader(config.WithRegistry(configs)),
+		configs:            configs,
+		configWatch:        defaultConfigWatchInterval,
+		ready:              make(chan struct{}),
+		initErrs:           new(errors.MultiError),
 	}
 
+	config.OnReload(app.reloader, func(conf logs.EnvConfig) error {
+		return logs.SetLevel(conf.Level)
+	})
+
 	for _, opt := range opts {
 		opt(app)
 	}
 
+	app.buildServers()
+
+	if err := app.initErrs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	if err := app.preflight(); err != nil {
+		return nil, err
+	}
+
+	if _, _, err := app.resolve(); err != nil {
+		return nil, err
+	}
+
 	return app, nil
 }
 
-func (app *App) AddServices(services ...any) {
+// WithService registers the service or closer with its options, see App.AddService.
+func WithService(service any, opts ...ServiceOption) AppOption {
+	return func(app *App) {
+		app.AddService(service, opts...)
+	}
+}
 
-	app.once.Do(func() {
-		app.services = make([]Service, 0, len(services))
-		app.closers = make([]Closer, 0, len(services))
-	})
+func (app *App) AddServices(services ...any) {
 
 	rejected := make([]string, 0)
 
 	for _, service := range services {
-		switch srv := service.(type) {
-		case Service:
-			app.services = append(app.services, srv)
-		case Closer:
-			app.closers = append(app.closers, srv)
-		default:
-			rejected = append(rejected, fmt.Sprintf("%T", srv))
+		if _, ok := app.addService(service); !ok {
+			rejected = append(rejected, fmt.Sprintf("%T", service))
 		}
 	}
 
@@ -73,21 +163,246 @@ func (app *App) AddServices(services ...any) {
 	}
 }
 
+// AddService registers the service or closer and applies options to it.
+// Options of a service registered earlier are extended.
+func (app *App) AddService(service any, opts ...ServiceOption) {
+
+	comp, ok := app.addService(service)
+	if !ok {
+		app.Log.Error("service with type doesn't implement gocherry.Service or gocherry.Closer interfaces",
+			slog.String("type", fmt.Sprintf("%T", service)),
+		)
+		return
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	for _, opt := range opts {
+		opt(comp)
+	}
+}
+
+// addService registers the service unless it's registered already and returns its component.
+func (app *App) addService(service any) (*component, bool) {
+
+	switch service.(type) {
+	case Service, Closer:
+	default:
+		return nil, false
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	comp := lookupComponent(app.components, service)
+	if comp == nil {
+		comp = newComponent(service)
+		app.components = append(app.components, comp)
+		if reloadable, ok := service.(config.Reloadable); ok {
+			app.reloader.Add(reloadable)
+		}
+	}
+	return comp, true
+}
+
+func (app *App) resolve() ([]*component, map[*component][]*component, error) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return resolve(app.components)
+}
+
+// Start starts services following their dependencies:
+// a service is started once every service it depends on is started.
 func (app *App) Start(ctx context.Context) error {
 
-	app.Log.Info("start app")
+	app.Log.Info("start app", buildInfo.LogAttr())
+	app.notify(systemd.Status("starting"))
 
-	for _, service := range app.services {
-		go service.Start(ctx)
+	order, deps, err := app.resolve()
+	if err != nil {
+		return err
 	}
+
+	app.mu.Lock()
+	app.failures = make(chan error, len(order))
+	app.stopping = make(chan struct{})
+	app.mu.Unlock()
+
+	if err := runHooks(ctx, "on start", app.hooks.onStart, true); err != nil {
+		app.Log.Error("failed to start app", logs.Error(err))
+		app.abortStart(ctx, order, deps)
+		return err
+	}
+
+	app.startProfile()
+	app.registerHealth(order)
+
+	go app.watchdog()
+
+	for _, comp := range order {
+		comp.reset()
+	}
+
+	for _, comp := range order {
+		go app.startComponent(ctx, comp, deps[comp])
+	}
+
+	go app.awaitReady(ctx, order)
+
 	return nil
 }
 
-func (app *App) Stop(ctx context.Context) {
+func (app *App) startComponent(ctx context.Context, comp *component, deps []*component) {
+
+	defer close(comp.exited)
+
+	log := app.Log.With(slog.String("service", comp.name))
+
+	comp.setState(StateWaiting)
+
+	if err := comp.waitDeps(ctx.Done(), deps); err != nil {
+		comp.setState(StateFailed)
+		log.Error("service is not started", logs.Error(err))
+		return
+	}
+
+	if comp.service == nil {
+		comp.setReady()
+		return
+	}
+
+	if err := app.supervise(ctx, comp, log); err != nil {
+		comp.setState(StateFailed)
+		log.Error("service failed", logs.Error(err))
+		app.fail(errors.Wrapf(err, "service %s failed", comp.name))
+	}
+}
+
+func (app *App) markStopping() {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.stopping == nil {
+		app.stopping = make(chan struct{})
+	}
+
+	select {
+	case <-app.stopping:
+	default:
+		close(app.stopping)
+	}
+}
+
+func (app *App) isStopping() bool {
+	select {
+	case <-app.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+func (app *App) fail(err error) {
+	select {
+	case app.failures <- err:
+	default:
+	}
+}
+
+// Run starts the app and blocks until SIGINT or SIGTERM is received,
+// ctx is done or any service fails. Then it stops the app within the stop timeout.
+// Meanwhile configs are reloaded on SIGHUP and on changes of the env file, see App.Reload,
+// and the binary is upgraded on SIGUSR2, see WithListenerHandoff.
+//
+// The returned error combines the service failure and the errors of stopping,
+// use ExitCode to get the process exit code for it.
+func (app *App) Run(ctx context.Context) error {
+
+	result := new(errors.MultiError)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	if app.handoff != nil {
+		signal.Notify(signals, syscall.SIGUSR2)
+	}
+	defer signal.Stop(signals)
+
+	if err := app.Start(ctx); err != nil {
+		result.AppendCriticalWrap(err, "failed to start app")
+		return result.ErrorOrNil()
+	}
+
+	go app.watchConfig()
+
+	stopCtx := context.WithoutCancel(ctx)
+
+wait:
+	for {
+		select {
+		case sig := <-signals:
+			switch sig {
+			case syscall.SIGHUP:
+				_ = app.Reload()
+				continue
+			case syscall.SIGUSR2:
+				if err := app.upgrade(ctx); err != nil {
+					continue
+				}
+			}
+			stopCtx = _ctx.WithStopCtx(stopCtx, sig)
+		case err := <-app.failures:
+			result.AppendCritical(err)
+		case <-ctx.Done():
+		}
+		break wait
+	}
+
+	stopCtx, cancel := context.WithTimeout(stopCtx, app.stopTimeout)
+	defer cancel()
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- app.Stop(stopCtx)
+	}()
+
+	select {
+	case err := <-stopped:
+		result.Append(err)
+	case <-stopCtx.Done():
+		result.AppendWrap(stopCtx.Err(), "app stop timeout exceeded")
+	}
+
+	return result.ErrorOrNil()
+}
+
+// ExitCode returns the process exit code for the error returned by App.Run or CLI.Execute.
+// Showing help is not a failure.
+func ExitCode(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return ExitCodeOK
+	}
+	return ExitCodeFailure
+}
+
+// Stop shuts the app down in phases:
+//   - marks the app not ready and waits for the drain delay;
+//   - stops listeners in parallel, each within its stop timeout;
+//   - stops workers;
+//   - closes closers.
+//
+// Services are stopped after every service depending on them: a service is moved
+// to the phase of its dependents if it's a later one, e.g. a listener a worker depends on
+// is stopped with workers. Within a phase services are stopped in the reverse order of their dependencies.
+func (app *App) Stop(ctx context.Context) error {
 
 	const msg = "app stopping"
 
 	result := new(errors.MultiError)
+	timeStart := time.Now()
+
+	app.markStopping()
+	app.notify(systemd.Stopping, systemd.Status("stopping"))
 
 	signal, ok := _ctx.StopFromCtx(ctx)
 	if ok {
@@ -96,24 +411,28 @@ func (app *App) Stop(ctx context.Context) {
 		app.Log.Info(msg)
 	}
 
-	for _, service := range app.services {
-		err := service.Stop(ctx)
-		if err != nil {
-			result.Append(err)
-		}
-	}
+	result.Append(runHooks(ctx, "before stop", app.hooks.beforeStop, false))
 
-	for _, closer := range app.closers {
-		err := closer.Close(ctx)
-		if err != nil {
-			result.Append(err)
-		}
+	order, deps, err := app.resolve()
+	if err != nil {
+		app.mu.Lock()
+		order = slices.Clone(app.components)
+		app.mu.Unlock()
 	}
 
-	err := result.ErrorOrNil()
+	app.drain(ctx, order)
+
+	result.Append(app.shutdown(ctx, order, deps))
+
+	app.stopProfile()
+
+	result.Append(runHooks(ctx, "after stop", app.hooks.afterStop, false))
+
+	err = result.ErrorOrNil()
 	if err != nil {
-		app.Log.Error("app stopped with errors", logs.Error(err))
+		app.Log.Error("app stopped with errors", logs.Error(err), logs.Took(timeStart))
 	} else {
-		app.Log.Info("app stopped")
+		app.Log.Info("app stopped", logs.Took(timeStart))
 	}
+	return err
 }
@@ -1,8 +1,11 @@
 package gocherry
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/vishenosik/gocherry/pkg/errors"
 	_http "github.com/vishenosik/gocherry/pkg/http"
 	"github.com/vishenosik/gocherry/pkg/logs"
 )
@@ -14,16 +17,30 @@ func WithHttpRoutes(handler http.Handler) AppOption {
 			return
 		}
 
-		server, err := _http.NewHttpServer(
-			handler,
-		)
+		app.mountHTTP(_http.BlankRoute, handler)
+	}
+}
 
-		if err != nil {
-			app.Log.Warn("failed to add http service", logs.Error(err))
+// WithRouter serves routes of the router, health routes are served next to them.
+// Config structs of the routes, e.g. the swagger one, are listed by App.Configs.
+func WithRouter(router *Router) AppOption {
+	return func(app *App) {
+		if router == nil {
+			app.Log.Warn("failed to add http service: router is nil")
 			return
 		}
 
-		app.AddServices(server)
+		app.addConfigs(router.Configs()...)
+		app.mountHTTP(_http.BlankRoute, router.Handler())
+	}
+}
+
+// WithPanicHook reports panics recovered by the http and grpc servers of the app, e.g. to an error tracker.
+func WithPanicHook(hook errors.PanicHook) AppOption {
+	return func(app *App) {
+		if hook != nil {
+			app.panicHooks = append(app.panicHooks, hook)
+		}
 	}
 }
 
@@ -36,6 +53,44 @@ func WithWorkerPool(subscriptions ...chan PoolTask) AppOption {
 			app.Log.Warn("failed to init worker pool", logs.Error(err))
 			return
 		}
+		app.addConfigs(PoolConfigEnv{})
 		app.AddServices(pool)
 	}
 }
+
+// WithStopTimeout sets the deadline App.Run gives App.Stop after the stop signal.
+func WithStopTimeout(timeout time.Duration) AppOption {
+	return func(app *App) {
+		if timeout > 0 {
+			app.stopTimeout = timeout
+		}
+	}
+}
+
+// WithDrainDelay sets the time App.Stop waits after marking the app not ready,
+// so load balancers stop sending traffic before listeners are stopped.
+func WithDrainDelay(delay time.Duration) AppOption {
+	return func(app *App) {
+		if delay >= 0 {
+			app.drainDelay = delay
+		}
+	}
+}
+
+// WithServiceStopTimeout sets the default time every service has to stop, see StopTimeout.
+func WithServiceStopTimeout(timeout time.Duration) AppOption {
+	return func(app *App) {
+		if timeout > 0 {
+			app.serviceStopTimeout = timeout
+		}
+	}
+}
+
+// WithLogger replaces the app logger set up from the environment.
+func WithLogger(log *slog.Logger) AppOption {
+	return func(app *App) {
+		if log != nil {
+			app.Log = log
+		}
+	}
+}
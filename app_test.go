@@ -0,0 +1,744 @@
+package gocherry
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/gocherry/pkg/config"
+)
+
+type journal struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (j *journal) add(entry string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, entry)
+}
+
+func (j *journal) list() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string(nil), j.entries...)
+}
+
+type testService struct {
+	name    string
+	journal *journal
+}
+
+func (s *testService) Start(context.Context) error {
+	s.journal.add("start " + s.name)
+	return nil
+}
+
+func (s *testService) Stop(context.Context) error {
+	s.journal.add("stop " + s.name)
+	return nil
+}
+
+type testCloser struct {
+	name    string
+	journal *journal
+}
+
+func (c *testCloser) Close(context.Context) error {
+	c.journal.add("close " + c.name)
+	return nil
+}
+
+// blockingService serves until it's stopped and doesn't report it's started
+type blockingService struct {
+	testService
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newBlockingService(name string, j *journal) *blockingService {
+	return &blockingService{testService: testService{name: name, journal: j}, stopped: make(chan struct{})}
+}
+
+func (s *blockingService) Start(ctx context.Context) error {
+	_ = s.testService.Start(ctx)
+	<-s.stopped
+	return nil
+}
+
+func (s *blockingService) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stopped) })
+	return s.testService.Stop(ctx)
+}
+
+// taggedCloser isn't comparable as it holds a map
+type taggedCloser struct {
+	*testCloser
+	tags map[string]string
+}
+
+type closerFunc func(context.Context) error
+
+func (f closerFunc) Close(ctx context.Context) error { return f(ctx) }
+
+func TestAppDependencies(t *testing.T) {
+
+	t.Run("start and stop order", func(t *testing.T) {
+		j := new(journal)
+
+		store := &testCloser{name: "store", journal: j}
+		cache := &testService{name: "cache", journal: j}
+		pool := &testService{name: "pool", journal: j}
+
+		app, err := NewApp(
+			WithService(pool, DependsOn(store, cache)),
+			WithService(cache, DependsOn(store)),
+			WithService(store),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		require.Eventually(t, func() bool { return len(j.list()) == 2 }, time.Second, time.Millisecond)
+
+		app.Stop(context.Background())
+
+		require.Equal(t, []string{
+			"start cache",
+			"start pool",
+			"stop pool",
+			"stop cache",
+			"close store",
+		}, j.list())
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		j := new(journal)
+
+		_, err := NewApp(
+			WithService(&testService{name: "pool", journal: j}, DependsOn(&testCloser{name: "store", journal: j})),
+		)
+		require.ErrorIs(t, err, ErrMissingDependency)
+	})
+
+	t.Run("blocking dependency", func(t *testing.T) {
+		j := new(journal)
+		server := newBlockingService("server", j)
+
+		app, err := NewApp(
+			WithService(server),
+			WithService(&testService{name: "pool", journal: j}, DependsOn(server)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		select {
+		case <-app.Ready():
+		case <-time.After(time.Second):
+			t.Fatal("app is not ready")
+		}
+		require.ElementsMatch(t, []string{"start server", "start pool"}, j.list())
+		require.Equal(t, StateRunning, app.Services()[0].State)
+
+		require.NoError(t, app.Stop(context.Background()))
+	})
+
+	t.Run("incomparable dependency", func(t *testing.T) {
+		j := new(journal)
+		store := taggedCloser{testCloser: &testCloser{name: "store", journal: j}, tags: map[string]string{}}
+
+		_, err := NewApp(
+			WithService(store),
+			WithService(&testService{name: "pool", journal: j}, DependsOn(store)),
+		)
+		require.ErrorIs(t, err, ErrIncomparableDependency)
+
+		// funcs are matched by their pointers
+		var flush closerFunc = func(context.Context) error {
+			j.add("close flush")
+			return nil
+		}
+		app, err := NewApp(
+			WithService(flush),
+			WithService(&testService{name: "pool", journal: j}, DependsOn(flush)),
+		)
+		require.NoError(t, err)
+		require.Len(t, app.Services(), 2)
+	})
+
+	t.Run("dependency cycle", func(t *testing.T) {
+		j := new(journal)
+
+		first := &testService{name: "first", journal: j}
+		second := &testService{name: "second", journal: j}
+
+		app, err := NewApp(
+			WithService(first, DependsOn(second)),
+		)
+		require.ErrorIs(t, err, ErrMissingDependency)
+		require.Nil(t, app)
+
+		app, err = NewApp()
+		require.NoError(t, err)
+
+		app.AddService(first, DependsOn(second))
+		app.AddService(second, DependsOn(first))
+
+		err = app.Start(context.Background())
+		require.ErrorIs(t, err, ErrDependencyCycle)
+		require.Empty(t, j.list())
+	})
+}
+
+type failingService struct {
+	err error
+}
+
+func (s *failingService) Start(context.Context) error { return s.err }
+
+func (s *failingService) Stop(context.Context) error { return nil }
+
+func TestAppRun(t *testing.T) {
+
+	t.Run("service failure", func(t *testing.T) {
+		errFailed := stderrors.New("address already in use")
+
+		app, err := NewApp(
+			WithService(&failingService{err: errFailed}),
+		)
+		require.NoError(t, err)
+
+		err = app.Run(context.Background())
+		require.ErrorIs(t, err, errFailed)
+		require.Equal(t, ExitCodeFailure, ExitCode(err))
+	})
+
+	t.Run("stop signal", func(t *testing.T) {
+		j := new(journal)
+
+		app, err := NewApp(
+			WithService(&testService{name: "service", journal: j}),
+			WithStopTimeout(time.Second),
+		)
+		require.NoError(t, err)
+
+		go func() {
+			assert.Eventually(t, func() bool { return len(j.list()) == 1 }, time.Second, time.Millisecond)
+			_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		}()
+
+		err = app.Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, ExitCodeOK, ExitCode(err))
+		require.Equal(t, []string{"start service", "stop service"}, j.list())
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		app, err := NewApp()
+		require.NoError(t, err)
+		require.NoError(t, app.Run(ctx))
+	})
+}
+
+type flakyService struct {
+	mu       sync.Mutex
+	attempts int
+	failures int
+	panics   bool
+}
+
+func (s *flakyService) Start(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.failures >= 0 && s.attempts > s.failures {
+		return nil
+	}
+	if s.panics {
+		panic("connection lost")
+	}
+	return stderrors.New("connection lost")
+}
+
+func (s *flakyService) Stop(context.Context) error { return nil }
+
+func (s *flakyService) Attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+func TestAppSupervisor(t *testing.T) {
+
+	backoff := RestartBackoff(time.Millisecond, time.Millisecond)
+
+	t.Run("restart on failure", func(t *testing.T) {
+		service := &flakyService{failures: 2}
+
+		app, err := NewApp(
+			WithService(service, RestartOnFailure(5), backoff),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		require.Eventually(t, func() bool { return service.Attempts() == 3 }, time.Second, time.Millisecond)
+		require.NoError(t, app.Stop(context.Background()))
+	})
+
+	t.Run("restart after panic", func(t *testing.T) {
+		service := &flakyService{failures: 1, panics: true}
+
+		app, err := NewApp(
+			WithService(service, RestartOnFailure(1), backoff),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		require.Eventually(t, func() bool { return service.Attempts() == 2 }, time.Second, time.Millisecond)
+		require.NoError(t, app.Stop(context.Background()))
+	})
+
+	t.Run("failed permanently", func(t *testing.T) {
+		service := &flakyService{failures: -1}
+
+		app, err := NewApp(
+			WithService(service, RestartOnFailure(2), backoff),
+		)
+		require.NoError(t, err)
+
+		err = app.Run(context.Background())
+		require.Error(t, err)
+		require.Equal(t, 3, service.Attempts())
+	})
+
+	t.Run("never restart", func(t *testing.T) {
+		service := &flakyService{failures: 1}
+
+		app, err := NewApp(
+			WithService(service, backoff),
+		)
+		require.NoError(t, err)
+
+		require.Error(t, app.Run(context.Background()))
+		require.Equal(t, 1, service.Attempts())
+	})
+
+	t.Run("clean exit", func(t *testing.T) {
+		j := new(journal)
+		migration := &testService{name: "migration", journal: j}
+		pool := &testService{name: "pool", journal: j}
+
+		app, err := NewApp(
+			WithService(migration),
+			WithService(pool, DependsOn(migration)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		require.Eventually(t, func() bool { return len(j.list()) == 2 }, time.Second, time.Millisecond)
+		require.Equal(t, []string{"start migration", "start pool"}, j.list())
+
+		services := app.Services()
+		require.Equal(t, StateStopped, services[0].State)
+
+		require.NoError(t, app.Stop(context.Background()))
+	})
+}
+
+func TestAppWorkerPool(t *testing.T) {
+
+	tasks := make(chan PoolTask)
+
+	app, err := NewApp(WithWorkerPool(tasks))
+	require.NoError(t, err)
+
+	require.NoError(t, app.Start(context.Background()))
+	select {
+	case <-app.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("app is not ready")
+	}
+
+	done := make(chan struct{})
+	tasks <- PoolTask{ID: "task", Func: func() { close(done) }}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task is not processed")
+	}
+
+	// the pool keeps running after its Start is called
+	services := app.Services()
+	require.Len(t, services, 1)
+	require.Equal(t, "*gocherry.Pool", services[0].Name)
+	require.Equal(t, StateRunning, services[0].State)
+
+	require.NoError(t, app.Stop(context.Background()))
+	require.Equal(t, StateStopped, app.Services()[0].State)
+}
+
+type healthyService struct {
+	testService
+}
+
+func (s *healthyService) CheckHealth(context.Context) error { return nil }
+
+func TestAppHealth(t *testing.T) {
+
+	service := &healthyService{testService{name: "service", journal: new(journal)}}
+
+	app, err := NewApp(
+		WithService(service, Named("service")),
+	)
+	require.NoError(t, err)
+
+	require.False(t, app.Health().Ready(context.Background()).Up())
+
+	require.NoError(t, app.Start(context.Background()))
+
+	select {
+	case <-app.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("app is not ready")
+	}
+
+	report := app.Health().Ready(context.Background())
+	require.True(t, report.Up())
+	require.Equal(t, "service", report.Components[0].Name)
+
+	require.NoError(t, app.Stop(context.Background()))
+	require.False(t, app.Health().Ready(context.Background()).Up())
+}
+
+type hangingService struct {
+	testService
+}
+
+func (s *hangingService) Stop(ctx context.Context) error {
+	<-ctx.Done()
+	s.journal.add("stop " + s.name)
+	return ctx.Err()
+}
+
+func TestAppShutdown(t *testing.T) {
+
+	t.Run("phases", func(t *testing.T) {
+		j := new(journal)
+
+		store := &testCloser{name: "store", journal: j}
+		worker := &testService{name: "worker", journal: j}
+		server := &testService{name: "server", journal: j}
+
+		app, err := NewApp(
+			WithService(store),
+			WithService(worker, InPhase(PhaseWorkers)),
+			WithService(server, DependsOn(store)),
+			WithDrainDelay(10*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		<-app.Ready()
+
+		require.NoError(t, app.Stop(context.Background()))
+		require.Equal(t, []string{"stop server", "stop worker", "close store"}, j.list()[2:])
+	})
+
+	t.Run("dependencies across phases", func(t *testing.T) {
+		j := new(journal)
+
+		server := &testService{name: "server", journal: j}
+		worker := &testService{name: "worker", journal: j}
+		store := &testCloser{name: "store", journal: j}
+		flusher := &testService{name: "flusher", journal: j}
+
+		app, err := NewApp(
+			WithService(server),
+			WithService(worker, InPhase(PhaseWorkers), DependsOn(server)),
+			WithService(store),
+			// stopped with closers, but before the store it depends on
+			WithService(flusher, InPhase(PhaseClosers), DependsOn(store)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		<-app.Ready()
+
+		require.NoError(t, app.Stop(context.Background()))
+		require.Equal(t, []string{"stop worker", "stop server", "stop flusher", "close store"}, j.list()[3:])
+	})
+
+	t.Run("stop timeout", func(t *testing.T) {
+		j := new(journal)
+
+		app, err := NewApp(
+			WithService(&hangingService{testService{name: "hanging", journal: j}}, StopTimeout(10*time.Millisecond)),
+			WithService(&testService{name: "server", journal: j}),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		<-app.Ready()
+
+		err = app.Stop(context.Background())
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ElementsMatch(t, []string{"stop hanging", "stop server"}, j.list()[2:])
+	})
+}
+
+func TestAppHooks(t *testing.T) {
+
+	hook := func(j *journal, entry string, err error) Hook {
+		return func(context.Context) error {
+			j.add(entry)
+			return err
+		}
+	}
+
+	t.Run("lifecycle", func(t *testing.T) {
+		j := new(journal)
+
+		app, err := NewApp(
+			WithService(&testService{name: "service", journal: j}),
+			WithOnStart(hook(j, "on start", nil)),
+			WithAfterStart(hook(j, "after start", nil)),
+			WithBeforeStop(hook(j, "before stop", nil)),
+			WithAfterStop(hook(j, "after stop", nil)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		<-app.Ready()
+		require.NoError(t, app.Stop(context.Background()))
+
+		require.Equal(t, []string{
+			"on start",
+			"start service",
+			"after start",
+			"before stop",
+			"stop service",
+			"after stop",
+		}, j.list())
+	})
+
+	t.Run("failed start", func(t *testing.T) {
+		j := new(journal)
+		errMigration := stderrors.New("migration failed")
+
+		app, err := NewApp(
+			WithService(&testService{name: "service", journal: j}),
+			WithService(&testCloser{name: "store", journal: j}),
+			WithOnStart(hook(j, "migrate", errMigration), hook(j, "warm up", nil)),
+			WithBeforeStop(hook(j, "before stop", nil)),
+		)
+		require.NoError(t, err)
+
+		err = app.Run(context.Background())
+		require.ErrorIs(t, err, errMigration)
+		// registered services and closers are released, though the app isn't started
+		require.Equal(t, []string{"migrate", "stop service", "close store"}, j.list())
+	})
+
+	t.Run("failed stop", func(t *testing.T) {
+		j := new(journal)
+		errFlush := stderrors.New("flush failed")
+
+		app, err := NewApp(
+			WithService(&testService{name: "service", journal: j}),
+			WithBeforeStop(hook(j, "before stop", errFlush)),
+			WithAfterStop(hook(j, "after stop", errFlush)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, app.Start(context.Background()))
+		<-app.Ready()
+
+		err = app.Stop(context.Background())
+		require.ErrorIs(t, err, errFlush)
+		require.Equal(t, []string{"start service", "before stop", "stop service", "after stop"}, j.list())
+	})
+}
+
+type reloadConfig struct {
+	Limit int `env:"TEST_RELOAD_LIMIT" env-default:"1"`
+}
+
+func (conf reloadConfig) Validate() error {
+	if conf.Limit < 1 {
+		return stderrors.New("limit must be positive")
+	}
+	return nil
+}
+
+type reloadableService struct {
+	testService
+	mu    sync.Mutex
+	limit int
+}
+
+func (s *reloadableService) ReloadConfig() any { return reloadConfig{} }
+
+func (s *reloadableService) Reload(conf any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = conf.(reloadConfig).Limit
+	return nil
+}
+
+func (s *reloadableService) Limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limit
+}
+
+func TestAppReload(t *testing.T) {
+
+	t.Run("reload", func(t *testing.T) {
+		service := &reloadableService{testService: testService{name: "service", journal: new(journal)}, limit: 1}
+
+		app, err := NewApp(WithService(service))
+		require.NoError(t, err)
+
+		t.Setenv("TEST_RELOAD_LIMIT", "5")
+		require.NoError(t, app.Reload())
+		require.Equal(t, 5, service.Limit())
+
+		t.Setenv("TEST_RELOAD_LIMIT", "0")
+		require.Error(t, app.Reload())
+		require.Equal(t, 5, service.Limit())
+	})
+
+	t.Run("prefixed config", func(t *testing.T) {
+		service := &reloadableService{testService: testService{name: "service", journal: new(journal)}, limit: 1}
+
+		app, err := NewApp(
+			WithConfigs(config.WithPrefix("WORKER_", reloadConfig{})),
+			WithService(service),
+		)
+		require.NoError(t, err)
+
+		t.Setenv("TEST_RELOAD_LIMIT", "5")
+		t.Setenv("WORKER_TEST_RELOAD_LIMIT", "7")
+		require.NoError(t, app.Reload())
+		require.Equal(t, 7, service.Limit())
+	})
+
+	t.Run("reload signal", func(t *testing.T) {
+		service := &reloadableService{testService: testService{name: "service", journal: new(journal)}, limit: 1}
+
+		app, err := NewApp(WithService(service), WithConfigWatch(0))
+		require.NoError(t, err)
+
+		t.Setenv("TEST_RELOAD_LIMIT", "3")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			assert.Eventually(t, func() bool { return len(service.journal.list()) == 1 }, time.Second, time.Millisecond)
+			_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+			assert.Eventually(t, func() bool { return service.Limit() == 3 }, time.Second, time.Millisecond)
+			cancel()
+		}()
+
+		require.NoError(t, app.Run(ctx))
+		require.Equal(t, 3, service.Limit())
+	})
+}
+
+func TestAppPreflight(t *testing.T) {
+
+	t.Run("invalid config", func(t *testing.T) {
+		t.Setenv("ADMIN_PORT", "0")
+		_, err := NewApp(WithAdminServer())
+		require.ErrorContains(t, err, "ADMIN_PORT: must satisfy gte=1")
+	})
+
+	t.Run("unknown variable", func(t *testing.T) {
+		t.Setenv("ADMIN_PROT", "9000")
+		_, err := NewApp(WithAdminServer())
+		require.NoError(t, err)
+	})
+
+	t.Run("custom environment", func(t *testing.T) {
+		t.Setenv("ENV", "staging")
+		_, err := NewApp()
+		require.NoError(t, err)
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		t.Setenv("ADMIN_PORT", "0")
+		_, err := NewApp(WithAdminServer(), WithoutConfigPreflight())
+		require.NoError(t, err)
+	})
+}
+
+func TestAppSystemdNotify(t *testing.T) {
+
+	path := t.TempDir() + "/notify.sock"
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	messages := make(chan string, 64)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			messages <- string(buf[:n])
+		}
+	}()
+
+	next := func() string {
+		for {
+			select {
+			case msg := <-messages:
+				// watchdog pings come in between
+				if msg != "WATCHDOG=1" {
+					return msg
+				}
+			case <-time.After(time.Second):
+				return ""
+			}
+		}
+	}
+
+	app, err := NewApp(
+		WithService(&testService{name: "service", journal: new(journal)}),
+		WithSystemdNotify(),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, app.Start(context.Background()))
+	require.Equal(t, "STATUS=starting", next())
+	require.Equal(t, "READY=1\nSTATUS=ready", next())
+
+	require.Eventually(t, func() bool {
+		select {
+		case msg := <-messages:
+			return msg == "WATCHDOG=1"
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, app.Stop(context.Background()))
+	require.Equal(t, "STOPPING=1\nSTATUS=stopping", next())
+}
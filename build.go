@@ -2,14 +2,26 @@ package gocherry
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"text/tabwriter"
 
+	"google.golang.org/grpc/metadata"
 	"gopkg.in/yaml.v2"
+
+	_grpc "github.com/vishenosik/gocherry/pkg/grpc"
+	_http "github.com/vishenosik/gocherry/pkg/http"
 )
 
 const (
 	_yaml_ = iota
 	_json_
+	_text_
 
 	unset = "not configured"
 )
@@ -24,11 +36,16 @@ var (
 )
 
 type BuildInfo struct {
+	Path      string `json:"path,omitempty" yaml:"path,omitempty"`
 	BuildDate string `json:"build_date,omitempty" yaml:"build_date,omitempty"`
 	GitBranch string `json:"git_branch,omitempty" yaml:"git_branch,omitempty"`
 	GitCommit string `json:"git_commit,omitempty" yaml:"git_commit,omitempty"`
 	GoVersion string `json:"go_version,omitempty" yaml:"go_version,omitempty"`
 	GitTag    string `json:"git_tag,omitempty" yaml:"git_tag,omitempty"`
+	// Modified is set when the binary is built from a working tree with uncommitted changes
+	Modified bool `json:"modified,omitempty" yaml:"modified,omitempty"`
+	// Modules maps dependency module paths to their versions
+	Modules map[string]string `json:"modules,omitempty" yaml:"modules,omitempty"`
 }
 
 var buildInfo BuildInfo
@@ -41,6 +58,69 @@ func init() {
 		GoVersion: GoVersion,
 		GitTag:    GitTag,
 	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		buildInfo = buildInfo.fill(info)
+	}
+}
+
+// GetBuildInfo returns info the binary is built with.
+func GetBuildInfo() BuildInfo {
+	return buildInfo
+}
+
+// fill sets values not configured by -ldflags from the info embedded by the go toolchain.
+func (bi BuildInfo) fill(info *debug.BuildInfo) BuildInfo {
+
+	bi.Path = info.Main.Path
+
+	if bi.GoVersion == unset {
+		bi.GoVersion = info.GoVersion
+	}
+
+	if bi.GitTag == unset && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		bi.GitTag = info.Main.Version
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if bi.GitCommit == unset {
+				bi.GitCommit = setting.Value
+			}
+		case "vcs.time":
+			if bi.BuildDate == unset {
+				bi.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			bi.Modified = setting.Value == "true"
+		}
+	}
+
+	if len(info.Deps) > 0 {
+		bi.Modules = make(map[string]string, len(info.Deps))
+		for _, dep := range info.Deps {
+			module := dep
+			if dep.Replace != nil {
+				module = dep.Replace
+			}
+			bi.Modules[dep.Path] = module.Version
+		}
+	}
+
+	return bi
+}
+
+// LogAttr groups build info attributes for logs, modules are omitted.
+func (bi BuildInfo) LogAttr() slog.Attr {
+	return slog.Group("build",
+		slog.String("git_tag", bi.GitTag),
+		slog.String("git_commit", bi.GitCommit),
+		slog.String("git_branch", bi.GitBranch),
+		slog.String("build_date", bi.BuildDate),
+		slog.String("go_version", bi.GoVersion),
+		slog.Bool("modified", bi.Modified),
+	)
 }
 
 func BuildInfoYaml(writer io.Writer) {
@@ -51,6 +131,28 @@ func BuildInfoJson(writer io.Writer) {
 	writeBuildInfo(writer, _json_)
 }
 
+// BuildInfoText writes build info as aligned key value lines followed by module versions.
+func BuildInfoText(writer io.Writer) {
+	writeBuildInfo(writer, _text_)
+}
+
+// BuildInfoMetric writes the build_info gauge in the prometheus text format,
+// its labels hold build info and its value is always 1.
+func BuildInfoMetric(writer io.Writer) {
+	labels := []string{
+		fmt.Sprintf("git_tag=%q", buildInfo.GitTag),
+		fmt.Sprintf("git_commit=%q", buildInfo.GitCommit),
+		fmt.Sprintf("git_branch=%q", buildInfo.GitBranch),
+		fmt.Sprintf("build_date=%q", buildInfo.BuildDate),
+		fmt.Sprintf("go_version=%q", buildInfo.GoVersion),
+		fmt.Sprintf("modified=\"%t\"", buildInfo.Modified),
+	}
+
+	fmt.Fprint(writer, "# HELP build_info A metric with a constant '1' value labeled by build info.\n")
+	fmt.Fprint(writer, "# TYPE build_info gauge\n")
+	fmt.Fprintf(writer, "build_info{%s} 1\n", strings.Join(labels, ","))
+}
+
 func writeBuildInfo(writer io.Writer, format int) {
 	var (
 		buf []byte
@@ -64,6 +166,9 @@ func writeBuildInfo(writer io.Writer, format int) {
 	case _json_:
 		buf, err = json.MarshalIndent(buildInfo, "", "  ")
 
+	case _text_:
+		buf = buildInfoText(buildInfo)
+
 	default:
 		return
 	}
@@ -73,3 +178,58 @@ func writeBuildInfo(writer io.Writer, format int) {
 	}
 	writer.Write(buf)
 }
+
+func buildInfoText(bi BuildInfo) []byte {
+
+	builder := new(strings.Builder)
+	writer := tabwriter.NewWriter(builder, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "path\t%s\n", bi.Path)
+	fmt.Fprintf(writer, "git_tag\t%s\n", bi.GitTag)
+	fmt.Fprintf(writer, "git_commit\t%s\n", bi.GitCommit)
+	fmt.Fprintf(writer, "git_branch\t%s\n", bi.GitBranch)
+	fmt.Fprintf(writer, "build_date\t%s\n", bi.BuildDate)
+	fmt.Fprintf(writer, "go_version\t%s\n", bi.GoVersion)
+	fmt.Fprintf(writer, "modified\t%t\n", bi.Modified)
+
+	writer.Flush()
+
+	if len(bi.Modules) > 0 {
+		fmt.Fprint(builder, "modules:\n")
+
+		paths := make([]string, 0, len(bi.Modules))
+		for path := range bi.Modules {
+			paths = append(paths, path)
+		}
+		slices.Sort(paths)
+
+		for _, path := range paths {
+			fmt.Fprintf(writer, "  %s\t%s\n", path, bi.Modules[path])
+		}
+	}
+
+	writer.Flush()
+	return []byte(builder.String())
+}
+
+// Build info headers set by WithBuildInfoHeaders, grpc metadata keys are lowercase.
+const (
+	HeaderBuildVersion = "X-Build-Version"
+	HeaderBuildCommit  = "X-Build-Commit"
+)
+
+// WithBuildInfoHeaders sets the git tag and commit the binary is built from
+// in headers of http responses and in header metadata of grpc responses.
+func WithBuildInfoHeaders() AppOption {
+	return func(app *App) {
+		headers := http.Header{}
+		headers.Set(HeaderBuildVersion, buildInfo.GitTag)
+		headers.Set(HeaderBuildCommit, buildInfo.GitCommit)
+
+		app.httpOptions = append(app.httpOptions, _http.WithResponseHeaders(headers))
+		app.grpcOptions = append(app.grpcOptions, _grpc.WithHeaderMetadata(metadata.Pairs(
+			HeaderBuildVersion, buildInfo.GitTag,
+			HeaderBuildCommit, buildInfo.GitCommit,
+		)))
+	}
+}
@@ -0,0 +1,82 @@
+package gocherry
+
+import (
+	"bytes"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfo(t *testing.T) {
+
+	info := &debug.BuildInfo{
+		GoVersion: "go1.24.2",
+		Main:      debug.Module{Path: "github.com/vishenosik/app", Version: "v1.2.0"},
+		Deps: []*debug.Module{
+			{Path: "github.com/go-chi/chi/v5", Version: "v5.2.1"},
+			{Path: "github.com/pkg/errors", Version: "v0.9.1", Replace: &debug.Module{Version: "v0.9.2"}},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "4403df3"},
+			{Key: "vcs.time", Value: "2026-10-18T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	unconfigured := BuildInfo{
+		BuildDate: unset,
+		GitBranch: unset,
+		GitCommit: unset,
+		GoVersion: unset,
+		GitTag:    unset,
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		require.Equal(t, BuildInfo{
+			Path:      "github.com/vishenosik/app",
+			BuildDate: "2026-10-18T10:00:00Z",
+			GitBranch: unset,
+			GitCommit: "4403df3",
+			GoVersion: "go1.24.2",
+			GitTag:    "v1.2.0",
+			Modified:  true,
+			Modules: map[string]string{
+				"github.com/go-chi/chi/v5": "v5.2.1",
+				"github.com/pkg/errors":    "v0.9.2",
+			},
+		}, unconfigured.fill(info))
+	})
+
+	t.Run("ldflags first", func(t *testing.T) {
+		configured := unconfigured
+		configured.GitCommit = "b48513c"
+		configured.GitTag = "v1.3.0"
+
+		filled := configured.fill(info)
+		require.Equal(t, "b48513c", filled.GitCommit)
+		require.Equal(t, "v1.3.0", filled.GitTag)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		filled := unconfigured.fill(info)
+		require.Equal(t, `path        github.com/vishenosik/app
+git_tag     v1.2.0
+git_commit  4403df3
+git_branch  not configured
+build_date  2026-10-18T10:00:00Z
+go_version  go1.24.2
+modified    true
+modules:
+  github.com/go-chi/chi/v5  v5.2.1
+  github.com/pkg/errors     v0.9.2
+`, string(buildInfoText(filled)))
+	})
+
+	t.Run("metric", func(t *testing.T) {
+		var buf bytes.Buffer
+		BuildInfoMetric(&buf)
+		require.Contains(t, buf.String(), `build_info{git_tag="`)
+		require.Contains(t, buf.String(), `go_version="`+buildInfo.GoVersion+`"`)
+	})
+}
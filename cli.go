@@ -0,0 +1,202 @@
+package gocherry
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrMissingCommand = errors.New("command is missing")
+)
+
+// Command is a subcommand of the app CLI, e.g. serve or migrate up.
+type Command struct {
+	Name string
+	// Usage is a one line description shown in the list of commands
+	Usage string
+	// ArgsUsage describes positional arguments in the help text, e.g. "[file]"
+	ArgsUsage string
+	// Flags defines flags of the command, they are parsed before Run is called
+	Flags func(flags *flag.FlagSet)
+	// Commands are nested subcommands, e.g. up and down of migrate
+	Commands []*Command
+	// Run runs the command with positional arguments left after flags.
+	// A command with subcommands runs when it's called without any.
+	Run func(ctx context.Context, out io.Writer, args []string) error
+}
+
+// CLI dispatches process arguments to commands.
+//
+//	cli := gocherry.NewCLI("app", gocherry.WithCommands(
+//		gocherry.ServeCommand(newApp),
+//		gocherry.ConfigCommand(configs...),
+//		gocherry.VersionCommand(),
+//	))
+//	err := cli.Execute(ctx, os.Args[1:])
+//	os.Exit(gocherry.ExitCode(err))
+type CLI struct {
+	root           *Command
+	out            io.Writer
+	defaultCommand string
+}
+
+type CLIOption func(*CLI)
+
+// WithCLIOutput sets the writer commands and help texts write to, os.Stdout by default.
+func WithCLIOutput(out io.Writer) CLIOption {
+	return func(cli *CLI) {
+		if out != nil {
+			cli.out = out
+		}
+	}
+}
+
+// WithCommands adds commands to the CLI, see CLI.AddCommands.
+func WithCommands(commands ...*Command) CLIOption {
+	return func(cli *CLI) {
+		cli.AddCommands(commands...)
+	}
+}
+
+// WithDefaultCommand sets the command run when the CLI is called without arguments.
+func WithDefaultCommand(name string) CLIOption {
+	return func(cli *CLI) {
+		cli.defaultCommand = name
+	}
+}
+
+func NewCLI(name string, opts ...CLIOption) *CLI {
+
+	cli := &CLI{
+		root: &Command{
+			Name: name,
+			Flags: func(flags *flag.FlagSet) {
+				flags.Func("config", configFileUsage, FlagConfigFile)
+			},
+		},
+		out: os.Stdout,
+	}
+
+	for _, opt := range opts {
+		opt(cli)
+	}
+
+	return cli
+}
+
+// AddCommands adds commands next to the ones the CLI is created with.
+// A command replaces the one with the same name.
+func (cli *CLI) AddCommands(commands ...*Command) {
+	for _, cmd := range commands {
+		if cmd == nil {
+			continue
+		}
+		cli.root.Commands = slices.DeleteFunc(cli.root.Commands, func(c *Command) bool {
+			return c.Name == cmd.Name
+		})
+		cli.root.Commands = append(cli.root.Commands, cmd)
+	}
+}
+
+// Execute runs the command the arguments point to. It returns flag.ErrHelp once help is shown.
+func (cli *CLI) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 && cli.defaultCommand != "" {
+		args = []string{cli.defaultCommand}
+	}
+	return cli.execute(ctx, cli.root, cli.root.Name, args)
+}
+
+func (cli *CLI) execute(ctx context.Context, cmd *Command, path string, args []string) error {
+
+	flags := flag.NewFlagSet(path, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	if cmd.Flags != nil {
+		cmd.Flags(flags)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(cli.out, "%s\n\n", err)
+		}
+		cli.usage(cmd, path, flags)
+		return err
+	}
+
+	args = flags.Args()
+
+	if len(cmd.Commands) == 0 || (len(args) == 0 && cmd.Run != nil) {
+		if cmd.Run == nil {
+			return nil
+		}
+		return cmd.Run(ctx, cli.out, args)
+	}
+
+	if len(args) == 0 {
+		cli.usage(cmd, path, flags)
+		return ErrMissingCommand
+	}
+
+	if args[0] == "help" {
+		cli.usage(cmd, path, flags)
+		return flag.ErrHelp
+	}
+
+	index := slices.IndexFunc(cmd.Commands, func(c *Command) bool { return c.Name == args[0] })
+	if index < 0 {
+		fmt.Fprintf(cli.out, "%s: %s\n\n", ErrUnknownCommand, args[0])
+		cli.usage(cmd, path, flags)
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+	}
+
+	sub := cmd.Commands[index]
+	return cli.execute(ctx, sub, path+" "+sub.Name, args[1:])
+}
+
+func (cli *CLI) usage(cmd *Command, path string, flags *flag.FlagSet) {
+
+	line := []string{"Usage:", path}
+	if len(cmd.Commands) > 0 {
+		line = append(line, "<command>")
+	}
+	if hasFlags(flags) {
+		line = append(line, "[flags]")
+	}
+	if cmd.ArgsUsage != "" {
+		line = append(line, cmd.ArgsUsage)
+	}
+	fmt.Fprintln(cli.out, strings.Join(line, " "))
+
+	if cmd.Usage != "" {
+		fmt.Fprintf(cli.out, "\n%s\n", cmd.Usage)
+	}
+
+	if len(cmd.Commands) > 0 {
+		fmt.Fprint(cli.out, "\nCommands:\n")
+		writer := tabwriter.NewWriter(cli.out, 0, 4, 2, ' ', 0)
+		for _, sub := range cmd.Commands {
+			fmt.Fprintf(writer, "  %s\t%s\n", sub.Name, sub.Usage)
+		}
+		writer.Flush()
+	}
+
+	if hasFlags(flags) {
+		fmt.Fprint(cli.out, "\nFlags:\n")
+		flags.SetOutput(cli.out)
+		flags.PrintDefaults()
+		flags.SetOutput(io.Discard)
+	}
+}
+
+func hasFlags(flags *flag.FlagSet) bool {
+	has := false
+	flags.VisitAll(func(*flag.Flag) { has = true })
+	return has
+}
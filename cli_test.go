@@ -0,0 +1,209 @@
+package gocherry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vishenosik/gocherry/pkg/config"
+)
+
+type testMigrator struct {
+	journal *journal
+}
+
+func (m *testMigrator) MigrateUp(context.Context) error {
+	m.journal.add("up")
+	return nil
+}
+
+func (m *testMigrator) MigrateDown(context.Context) error {
+	m.journal.add("down")
+	return nil
+}
+
+func (m *testMigrator) MigrateStatus(_ context.Context, writer io.Writer) error {
+	_, err := io.WriteString(writer, "applied 00001_init.sql\n")
+	return err
+}
+
+func (m *testMigrator) Close(context.Context) error {
+	m.journal.add("close")
+	return nil
+}
+
+type invalidConfig struct {
+	Name string `env:"INVALID_CONFIG_NAME" desc:"Name"`
+}
+
+func (conf invalidConfig) Validate() error {
+	if conf.Name == "" {
+		return stderrors.New("name is required")
+	}
+	return nil
+}
+
+func TestCLI(_t *testing.T) {
+
+	t := &T{_t}
+
+	newCLI := func(buf *bytes.Buffer, j *journal, opts ...CLIOption) *CLI {
+		return NewCLI("app", append([]CLIOption{
+			WithCLIOutput(buf),
+			WithCommands(
+				MigrateCommand(func() (Migrator, error) { return &testMigrator{journal: j}, nil }),
+				ConfigCommand(TestConfig{}),
+				VersionCommand(),
+			),
+		}, opts...)...)
+	}
+
+	t.Run("help", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"help"})
+		require.ErrorIs(t, err, flag.ErrHelp)
+		require.Equal(t, ExitCodeOK, ExitCode(err))
+		require.Contains(t, buf.String(), "Usage: app <command>")
+		require.Contains(t, buf.String(), "migrate  Run store migrations")
+	})
+
+	t.Run("command help", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"version", "-h"})
+		require.ErrorIs(t, err, flag.ErrHelp)
+		require.Contains(t, buf.String(), "Usage: app version [flags]")
+		require.Contains(t, buf.String(), "-format string")
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"migrate", "sideways"})
+		require.ErrorIs(t, err, ErrUnknownCommand)
+		require.Equal(t, ExitCodeFailure, ExitCode(err))
+		require.Contains(t, buf.String(), "Usage: app migrate <command>")
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), nil)
+		require.ErrorIs(t, err, ErrMissingCommand)
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		var buf bytes.Buffer
+		j := new(journal)
+		cli := newCLI(&buf, j)
+
+		require.NoError(t, cli.Execute(context.Background(), []string{"migrate", "up"}))
+		require.NoError(t, cli.Execute(context.Background(), []string{"migrate", "down"}))
+		require.NoError(t, cli.Execute(context.Background(), []string{"migrate", "status"}))
+		require.Equal(t, []string{"up", "close", "down", "close", "close"}, j.list())
+		require.Equal(t, "applied 00001_init.sql\n", buf.String())
+	})
+
+	t.Run("version", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"version", "--format=json"})
+		require.NoError(t, err)
+
+		var info BuildInfo
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &info))
+		require.Equal(t, buildInfo, info)
+
+		err = newCLI(&buf, new(journal)).Execute(context.Background(), []string{"version", "--format=toml"})
+		require.Error(t, err)
+	})
+
+	t.Run("config show", func(t *testing.T) {
+		var buf bytes.Buffer
+		t.Setenv("GREETING", "Hello")
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"config", "show"})
+		require.NoError(t, err)
+		require.Equal(t, "\n#=== Test config ===#\n\nVERBOSE=true # default\nGREETING=Hello # env\nLEVEL=123 # default\n", buf.String())
+	})
+
+	t.Run("config file", func(t *testing.T) {
+		defer config.ResetLayers()
+
+		file := filepath.Join(t.TempDir(), "app.yaml")
+		require.NoError(t, os.WriteFile(file, []byte("greeting: Hi\nlevel: 7\n"), 0o600))
+		t.Setenv("LEVEL", "8")
+
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"--config", file, "config", "show"})
+		require.NoError(t, err)
+		require.Equal(t, "\n#=== Test config ===#\n\nVERBOSE=true # default\nGREETING=Hi # file\nLEVEL=8 # env\n", buf.String())
+	})
+
+	t.Run("config check", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := newCLI(&buf, new(journal), WithCommands(ConfigCommand(invalidConfig{})))
+
+		err := cli.Execute(context.Background(), []string{"config", "check"})
+		require.ErrorContains(t, err, "name is required")
+		require.Equal(t, "invalid  -  gocherry.invalidConfig  name is required\n", buf.String())
+
+		buf.Reset()
+		t.Setenv("INVALID_CONFIG_NAME", "cherry")
+		require.NoError(t, cli.Execute(context.Background(), []string{"config", "check"}))
+		require.Equal(t, "config is valid\n", buf.String())
+	})
+
+	t.Run("config gen", func(t *testing.T) {
+		var buf bytes.Buffer
+		filename := filepath.Join(t.TempDir(), "example.env")
+
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"config", "gen", filename})
+		require.NoError(t, err)
+		require.Empty(t, buf.String())
+
+		conf, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		require.Equal(t, configInfo, string(conf))
+	})
+
+	t.Run("config gen format", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"config", "gen", "--format=compose"})
+		require.NoError(t, err)
+		require.Equal(t, "environment:\n  VERBOSE: \"true\"\n  GREETING: Greeting\n  LEVEL: \"123\"\n", buf.String())
+	})
+
+	t.Run("config gen registered", func(t *testing.T) {
+		config.AddStructs(invalidConfig{})
+
+		var buf bytes.Buffer
+		err := newCLI(&buf, new(journal)).Execute(context.Background(), []string{"config", "gen"})
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "INVALID_CONFIG_NAME=")
+	})
+
+	t.Run("custom default command", func(t *testing.T) {
+		var buf bytes.Buffer
+		var greeting string
+
+		cli := newCLI(&buf, new(journal),
+			WithCommands(&Command{
+				Name:  "greet",
+				Usage: "Greet somebody",
+				Flags: func(flags *flag.FlagSet) {
+					flags.StringVar(&greeting, "greeting", "Hello", "greeting")
+				},
+				Run: func(_ context.Context, out io.Writer, args []string) error {
+					_, err := io.WriteString(out, greeting+", "+args[0])
+					return err
+				},
+			}),
+		)
+
+		require.NoError(t, cli.Execute(context.Background(), []string{"greet", "-greeting=Hi", "cherry"}))
+		require.Equal(t, "Hi, cherry", buf.String())
+	})
+}
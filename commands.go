@@ -0,0 +1,168 @@
+package gocherry
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/vishenosik/gocherry/pkg/config"
+)
+
+// Migrator runs migrations of a store, e.g. *sql.SqliteStore.
+type Migrator interface {
+	// MigrateUp applies pending migrations.
+	MigrateUp(ctx context.Context) error
+	// MigrateDown rolls the latest applied migration back.
+	MigrateDown(ctx context.Context) error
+	// MigrateStatus writes the state of every migration.
+	MigrateStatus(ctx context.Context, writer io.Writer) error
+}
+
+// ServeCommand runs the app created by newApp until it's stopped, see App.Run.
+func ServeCommand(newApp func() (*App, error)) *Command {
+	return &Command{
+		Name:  "serve",
+		Usage: "Run the app until it's stopped",
+		Run: func(ctx context.Context, _ io.Writer, _ []string) error {
+			app, err := newApp()
+			if err != nil {
+				return err
+			}
+			return app.Run(ctx)
+		},
+	}
+}
+
+// MigrateCommand runs migrations of the store created by newMigrator.
+func MigrateCommand(newMigrator func() (Migrator, error)) *Command {
+
+	run := func(migrate func(ctx context.Context, migrator Migrator, out io.Writer) error) func(context.Context, io.Writer, []string) error {
+		return func(ctx context.Context, out io.Writer, _ []string) error {
+			migrator, err := newMigrator()
+			if err != nil {
+				return err
+			}
+			if closer, ok := migrator.(Closer); ok {
+				defer closer.Close(ctx)
+			}
+			return migrate(ctx, migrator, out)
+		}
+	}
+
+	return &Command{
+		Name:  "migrate",
+		Usage: "Run store migrations",
+		Commands: []*Command{
+			{
+				Name:  "up",
+				Usage: "Apply pending migrations",
+				Run: run(func(ctx context.Context, migrator Migrator, _ io.Writer) error {
+					return migrator.MigrateUp(ctx)
+				}),
+			},
+			{
+				Name:  "down",
+				Usage: "Roll the latest migration back",
+				Run: run(func(ctx context.Context, migrator Migrator, _ io.Writer) error {
+					return migrator.MigrateDown(ctx)
+				}),
+			},
+			{
+				Name:  "status",
+				Usage: "Show migrations state",
+				Run: run(func(ctx context.Context, migrator Migrator, out io.Writer) error {
+					return migrator.MigrateStatus(ctx, out)
+				}),
+			},
+		},
+	}
+}
+
+// ConfigCommand inspects config structs along with the ones added by config.AddStructs.
+func ConfigCommand(structs ...any) *Command {
+
+	all := func() []any {
+		return slices.Concat(structs, config.Structs())
+	}
+
+	format := config.FormatEnv
+
+	return &Command{
+		Name:  "config",
+		Usage: "Inspect app config",
+		Commands: []*Command{
+			{
+				Name:  "show",
+				Usage: "Show effective config values and their sources, secrets are redacted",
+				Run: func(_ context.Context, out io.Writer, _ []string) error {
+					return config.EffectiveValuesEnv(out, all()...)
+				},
+			},
+			{
+				Name:  "check",
+				Usage: "Report missing, invalid or unknown config variables",
+				Run: func(_ context.Context, out io.Writer, _ []string) error {
+					report, err := config.Preflight(all()...)
+					if err != nil {
+						return err
+					}
+					report.Write(out)
+					return report.Err()
+				},
+			},
+			{
+				Name:      "gen",
+				Usage:     "Generate config schema, to stdout unless the file is set",
+				ArgsUsage: "[file]",
+				Flags: func(flags *flag.FlagSet) {
+					format = config.FormatEnv
+					flags.Func("format", configFormatUsage+" (default env)", func(name string) (err error) {
+						format, err = config.ParseFormat(name)
+						return err
+					})
+				},
+				Run: func(_ context.Context, out io.Writer, args []string) error {
+					if len(args) > 0 {
+						file, err := os.Create(args[0])
+						if err != nil {
+							return err
+						}
+						defer file.Close()
+						out = file
+					}
+					return config.WriteSchema(out, format, all()...)
+				},
+			},
+		},
+	}
+}
+
+// VersionCommand shows build info.
+func VersionCommand() *Command {
+
+	var format string
+
+	return &Command{
+		Name:  "version",
+		Usage: "Show build info",
+		Flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&format, "format", "yaml", "output format: json, yaml or text")
+		},
+		Run: func(_ context.Context, out io.Writer, _ []string) error {
+			switch format {
+			case "yaml":
+				BuildInfoYaml(out)
+			case "json":
+				BuildInfoJson(out)
+			case "text":
+				BuildInfoText(out)
+			default:
+				return fmt.Errorf("unknown build info format %q", format)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,249 @@
+package gocherry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrDependencyCycle   = errors.New("services dependency cycle")
+	ErrMissingDependency = errors.New("service dependency is not registered")
+	// ErrIncomparableDependency is returned for dependencies which can't be matched to registered values,
+	// e.g. struct values holding maps, slices or funcs. Pointers to them are matched.
+	ErrIncomparableDependency = errors.New("service dependency is not comparable")
+)
+
+// StartNotifier is implemented by services whose Start blocks while serving.
+// The returned channel is closed once the service is able to accept work,
+// dependent services are started only after that. Services which don't implement it
+// are considered started once their Start is called.
+type StartNotifier interface {
+	Started() <-chan struct{}
+}
+
+// ServiceOption configures the way App runs a registered service or closer.
+type ServiceOption func(*component)
+
+// DependsOn declares that the service must be started after deps and stopped before them.
+// Every dependency must be registered in the App as a Service or a Closer.
+// Dependencies are matched by ==, so struct values holding maps, slices or funcs
+// are rejected with ErrIncomparableDependency, pointers to them are matched.
+func DependsOn(deps ...any) ServiceOption {
+	return func(c *component) {
+		c.deps = append(c.deps, deps...)
+	}
+}
+
+// Named sets the service name used in logs and errors instead of its type name.
+func Named(name string) ServiceOption {
+	return func(c *component) {
+		if name != "" {
+			c.name = name
+		}
+	}
+}
+
+type component struct {
+	name    string
+	value   any
+	service Service
+	closer  Closer
+	deps    []any
+	restart restartPolicy
+
+	phase       *Phase
+	stopTimeout time.Duration
+
+	healthRegistered bool
+
+	state    atomic.Value
+	restarts atomic.Int32
+
+	// ready is closed once the component is started
+	ready     chan struct{}
+	readyOnce sync.Once
+	// exited is closed when Service.Start returns
+	exited chan struct{}
+}
+
+func newComponent(value any) *component {
+	c := &component{
+		name:    fmt.Sprintf("%T", value),
+		value:   value,
+		restart: defaultRestartPolicy(),
+	}
+	c.setState(StateRegistered)
+	if srv, ok := value.(Service); ok {
+		c.service = srv
+	}
+	if closer, ok := value.(Closer); ok {
+		c.closer = closer
+	}
+	return c
+}
+
+// is reports whether the component holds the value. Maps, slices and funcs are matched by their pointers.
+func (c *component) is(value any) bool {
+	if c.value == nil || value == nil || reflect.TypeOf(c.value) != reflect.TypeOf(value) {
+		return false
+	}
+	if !identifiable(value) {
+		return false
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Func:
+		return reflect.ValueOf(c.value).Pointer() == reflect.ValueOf(value).Pointer()
+	}
+	return c.value == value
+}
+
+// identifiable reports whether the value can be matched to registered ones:
+// it's comparable, a map, a slice or a func.
+func identifiable(value any) bool {
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Func:
+		return true
+	}
+	return reflect.ValueOf(value).Comparable()
+}
+
+func (c *component) reset() {
+	c.ready = make(chan struct{})
+	c.readyOnce = sync.Once{}
+	c.exited = make(chan struct{})
+}
+
+func (c *component) setReady() {
+	c.setState(StateRunning)
+	c.readyOnce.Do(func() {
+		close(c.ready)
+	})
+}
+
+// setExited marks the service stopped after its Start returned nil,
+// dependents waiting for it are started as it did its job.
+func (c *component) setExited() {
+	c.setState(StateStopped)
+	c.readyOnce.Do(func() {
+		close(c.ready)
+	})
+}
+
+func (c *component) setState(state ServiceState) {
+	c.state.Store(state)
+}
+
+func (c *component) getState() ServiceState {
+	state, _ := c.state.Load().(ServiceState)
+	return state
+}
+
+// waitDeps blocks until every dependency of the component is started.
+func (c *component) waitDeps(done <-chan struct{}, deps []*component) error {
+	for _, dep := range deps {
+		select {
+		case <-dep.ready:
+		case <-dep.exited:
+			select {
+			case <-dep.ready:
+			default:
+				return fmt.Errorf("dependency %s failed to start", dep.name)
+			}
+		case <-done:
+			return fmt.Errorf("dependency %s is not started", dep.name)
+		}
+	}
+	return nil
+}
+
+// resolve sorts components so that every component follows its dependencies.
+// Components without relations keep the registration order.
+func resolve(components []*component) ([]*component, map[*component][]*component, error) {
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	deps := make(map[*component][]*component, len(components))
+
+	for _, comp := range components {
+		for _, dep := range comp.deps {
+			if dep != nil && !identifiable(dep) {
+				return nil, nil, fmt.Errorf("%w: %s depends on %T, pass a pointer to it", ErrIncomparableDependency, comp.name, dep)
+			}
+			found := lookupComponent(components, dep)
+			if found == nil {
+				return nil, nil, fmt.Errorf("%w: %s depends on %T", ErrMissingDependency, comp.name, dep)
+			}
+			deps[comp] = append(deps[comp], found)
+		}
+	}
+
+	state := make(map[*component]int, len(components))
+	order := make([]*component, 0, len(components))
+	path := make([]*component, 0)
+
+	var visit func(comp *component) error
+	visit = func(comp *component) error {
+		switch state[comp] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, cyclePath(path, comp))
+		}
+
+		state[comp] = visiting
+		path = append(path, comp)
+
+		for _, dep := range deps[comp] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[comp] = visited
+		order = append(order, comp)
+		return nil
+	}
+
+	for _, comp := range components {
+		if err := visit(comp); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return order, deps, nil
+}
+
+func lookupComponent(components []*component, value any) *component {
+	for _, comp := range components {
+		if comp.is(value) {
+			return comp
+		}
+	}
+	return nil
+}
+
+func cyclePath(path []*component, last *component) string {
+	names := make([]string, 0, len(path)+1)
+	start := 0
+	for i, comp := range path {
+		if comp == last {
+			start = i
+			break
+		}
+	}
+	for _, comp := range path[start:] {
+		names = append(names, comp.name)
+	}
+	names = append(names, last.name)
+	return strings.Join(names, " -> ")
+}
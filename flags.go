@@ -11,12 +11,20 @@ import (
 )
 
 var (
-	ErrSuccessExit = errors.New("success and exit with code 0")
+	ErrSuccessExit   = errors.New("success and exit with code 0")
+	ErrInvalidConfig = errors.New("config is invalid")
 )
 
 type flagset struct {
 	writer io.Writer
 	*flag.FlagSet
+	// actions run once every flag is parsed, so they see values of flags following them
+	actions []func() error
+}
+
+// then defers the action until every flag is parsed.
+func (f *flagset) then(action func() error) {
+	f.actions = append(f.actions, action)
 }
 
 func (f *flagset) Parse(arguments []string) error {
@@ -32,11 +40,21 @@ func (f *flagset) Parse(arguments []string) error {
 		if strings.Contains(err.Error(), ErrSuccessExit.Error()) {
 			return ErrSuccessExit
 		}
+		// the report is written already, usage adds nothing to it
+		if strings.Contains(err.Error(), ErrInvalidConfig.Error()) {
+			return ErrInvalidConfig
+		}
 
 		print(err.Error())
 		return err
 	}
 
+	for _, action := range f.actions {
+		if err := action(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -49,34 +67,114 @@ func AppFlags(writer io.Writer) func(*flagset) {
 	}
 }
 
+// ConfigFlags defines flags showing the config schema and a flag for every variable
+// of the structs and the registered ones, e.g. --http.port for HTTP_PORT.
 func ConfigFlags(writer io.Writer, structs ...any) func(*flagset) {
 
 	return func(f *flagset) {
-		f.BoolFunc("config.info", "Show config schema information", FlagConfigInfoEnv(writer, structs...))
-		f.Func("config.gen", "Generate config schema", FlagConfigGenEnv(structs...))
+
+		format := config.FormatEnv
+
+		f.Func("config", configFileUsage, FlagConfigFile)
+		f.Func("config.format", configFormatUsage, func(name string) (err error) {
+			format, err = config.ParseFormat(name)
+			return err
+		})
+		f.BoolFunc("config.info", "Show config schema information", func(string) error {
+			f.then(func() error { return FlagConfigInfo(writer, format, structs...)("") })
+			return nil
+		})
+		f.BoolFunc("config.show", "Show effective config values and their sources, secrets are redacted", func(string) error {
+			f.then(func() error { return FlagConfigShow(writer, structs...)("") })
+			return nil
+		})
+		f.BoolFunc("config.check", "Check config values and report missing, invalid or unknown variables", func(string) error {
+			f.then(func() error { return FlagConfigCheck(writer, structs...)("") })
+			return nil
+		})
+		f.Func("config.gen", "Generate config schema to the file, named after config.format if empty", func(filename string) error {
+			f.then(func() error { return FlagConfigGen(format, structs...)(filename) })
+			return nil
+		})
+		config.BindFlags(f.FlagSet, append(structs, config.Structs()...)...)
 	}
 }
 
+const (
+	configFileUsage   = "YAML, JSON or TOML config file, $" + config.EnvConfigFile + " by default"
+	configFormatUsage = "Config schema format: env, jsonschema, markdown, configmap or compose"
+)
+
+// FlagConfigFile selects the config file configs are read from.
+func FlagConfigFile(path string) error {
+	config.SetConfigFile(path)
+	return nil
+}
+
 func FlagConfigInfoEnv(writer io.Writer, structs ...any) func(string) error {
+	return FlagConfigInfo(writer, config.FormatEnv, structs...)
+}
+
+// FlagConfigInfo writes config schema of the structs and the registered ones in the format.
+func FlagConfigInfo(writer io.Writer, format config.Format, structs ...any) func(string) error {
 	return func(string) error {
-		config.ConfigInfoEnv(writer, structs...)
+		if err := config.WriteSchema(writer, format, structs...); err != nil {
+			return err
+		}
+		return ErrSuccessExit
+	}
+}
+
+// FlagConfigShow writes effective values of the structs and the registered ones with their sources:
+// defaults, the config file, the env file, the environment or flags.
+func FlagConfigShow(writer io.Writer, structs ...any) func(string) error {
+	return func(string) error {
+		if err := config.EffectiveValuesEnv(writer, append(structs, config.Structs()...)...); err != nil {
+			return err
+		}
+		return ErrSuccessExit
+	}
+}
+
+// FlagConfigCheck writes the preflight report of the structs and the registered ones.
+// It returns ErrInvalidConfig if any variable is missing or invalid.
+func FlagConfigCheck(writer io.Writer, structs ...any) func(string) error {
+	return func(string) error {
+		report, err := config.Preflight(append(structs, config.Structs()...)...)
+		if err != nil {
+			return err
+		}
+		report.Write(writer)
+		if report.Err() != nil {
+			return ErrInvalidConfig
+		}
 		return ErrSuccessExit
 	}
 }
 
 func FlagConfigGenEnv(structs ...any) func(string) error {
+	return FlagConfigGen(config.FormatEnv, structs...)
+}
+
+// FlagConfigGen writes config schema in the format to the file, see config.Format.Filename
+// for the default one.
+func FlagConfigGen(format config.Format, structs ...any) func(string) error {
 	return func(filename string) error {
 		if filename == "" {
-			filename = "example.env"
+			filename = format.Filename()
 		}
 		file, err := os.Create(filename)
 		if err != nil {
 			return err
 		}
-		return FlagConfigInfoEnv(file, structs...)(filename)
+		defer file.Close()
+		return FlagConfigInfo(file, format, structs...)(filename)
 	}
 }
 
+// Flags parses top-level flags and exits once a flag is handled.
+//
+// Deprecated: use CLI, its commands return errors instead of exiting.
 func Flags(writer io.Writer, args []string, flagsets ...func(*flagset)) {
 	if err := parseFlags(writer, args, flagsets...); err != nil {
 		if errors.Is(err, flag.ErrHelp) || errors.Is(err, ErrSuccessExit) {
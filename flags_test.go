@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"flag"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -60,6 +61,56 @@ func TestConfigFlags(_t *testing.T) {
 		require.Equal(t, buf.String(), configInfo)
 	})
 
+	t.Run("config check", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := parseFlags(&buf, []string{"-config.check"}, ConfigFlags(&buf, TestConfig{}))
+		require.ErrorIs(t, err, ErrSuccessExit)
+		require.Equal(t, "config is valid\n", buf.String())
+
+		buf.Reset()
+		t.Setenv("LEVEL", "high")
+		err = parseFlags(&buf, []string{"-config.check"}, ConfigFlags(&buf, TestConfig{}))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		require.Contains(t, buf.String(), "invalid  LEVEL")
+		require.NotContains(t, buf.String(), "Usage")
+	})
+
+	t.Run("config check before config file", func(t *testing.T) {
+		t.Cleanup(config.ResetLayers)
+
+		file := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(file, []byte("level: high\n"), 0o600))
+
+		var buf bytes.Buffer
+		// the check runs once every flag is parsed, so it sees the config file
+		err := parseFlags(&buf, []string{"-config.check", "-config=" + file}, ConfigFlags(&buf, TestConfig{}))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		require.Contains(t, buf.String(), "invalid  LEVEL")
+
+		buf.Reset()
+		err = parseFlags(&buf, []string{"-config.check", "-level=7"}, ConfigFlags(&buf, TestConfig{}))
+		require.ErrorIs(t, err, ErrSuccessExit)
+		require.Equal(t, "config is valid\n", buf.String())
+	})
+
+	t.Run("config format", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "CONFIG.md")
+
+		var buf bytes.Buffer
+		// the format applies to config.gen preceding it
+		err := parseFlags(&buf, []string{"-config.gen", filename, "-config.format", "markdown"},
+			ConfigFlags(&buf, TestConfig{}),
+		)
+		require.ErrorIs(t, err, ErrSuccessExit)
+
+		conf, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		require.Contains(t, string(conf), "| `GREETING` | `string` | `Greeting` |  | Greeting description |")
+
+		err = parseFlags(&buf, []string{"-config.format", "xml"}, ConfigFlags(&buf, TestConfig{}))
+		require.ErrorContains(t, err, "unknown config format")
+	})
+
 	t.Run("config gen file", func(t *testing.T) {
 
 		const filename = "config.env"
@@ -84,6 +135,59 @@ func TestConfigFlags(_t *testing.T) {
 
 }
 
+func TestConfigVariableFlags(_t *testing.T) {
+
+	t := &T{_t}
+
+	t.Run("override variable", func(t *testing.T) {
+		defer config.ResetLayers()
+		t.Setenv("GREETING", "Hello")
+
+		var buf bytes.Buffer
+		err := parseFlags(&buf, []string{"-greeting", "Hi", "-verbose=false"},
+			ConfigFlags(&buf, TestConfig{}),
+		)
+		require.NoError(t, err)
+
+		var conf TestConfig
+		require.NoError(t, config.ReadConfigEnv(&conf))
+		require.Equal(t, TestConfig{Verbose: false, Greeting: "Hi", Level: 123}, conf)
+	})
+
+	t.Run("config show", func(t *testing.T) {
+		defer config.ResetLayers()
+		t.Setenv("LEVEL", "5")
+
+		var buf bytes.Buffer
+		// the flag following config.show is shown
+		err := parseFlags(&buf, []string{"-config.show", "-greeting", "Hi"}, ConfigFlags(&buf, TestConfig{}))
+		require.ErrorIs(t, err, ErrSuccessExit)
+		require.Equal(t, "\n#=== Test config ===#\n\nVERBOSE=true # default\nGREETING=Hi # flag\nLEVEL=5 # env\n", buf.String())
+	})
+
+	t.Run("registered struct", func(t *testing.T) {
+		defer config.ResetLayers()
+		config.AddStructs(TestConfig{})
+
+		var buf bytes.Buffer
+		require.NoError(t, parseFlags(&buf, []string{"-level", "7"}, ConfigFlags(&buf)))
+
+		var conf TestConfig
+		require.NoError(t, config.ReadConfigEnv(&conf))
+		require.Equal(t, 7, conf.Level)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		defer config.ResetLayers()
+
+		var buf bytes.Buffer
+		err := parseFlags(&buf, []string{"-level", "high"}, ConfigFlags(&buf, TestConfig{}))
+		require.ErrorContains(t, err, "LEVEL")
+		require.Contains(t, buf.String(), "-level value")
+		require.Contains(t, buf.String(), "Level description ($LEVEL) (default 123)")
+	})
+}
+
 type TestConfig struct {
 	Verbose  bool   `env:"VERBOSE" env-default:"true" desc:"Verbose description"`
 	Greeting string `env:"GREETING" env-default:"Greeting" desc:"Greeting description"`
@@ -0,0 +1,201 @@
+// Package gocherrytest boots gocherry apps in process for integration tests.
+//
+// The app serves http and grpc on ephemeral ports, uses an in-memory sqlite store
+// and an in-memory cache and logs nothing, so tests neither hardcode ports nor sleep
+// waiting for servers to start:
+//
+//	h := gocherrytest.New(t, gocherrytest.WithAppOptions(
+//		gocherry.WithModules(usersModule()),
+//	))
+//	resp, err := http.Get(h.BaseURL + "/api/users")
+//
+// New doesn't change the environment, so harnesses may be created in parallel tests.
+// The app and its servers log with the harness logger, middlewares logging requests are expected
+// to get it from the module registrar, e.g. _http.RequestLogger(_http.WithRequestLog(app.Logger())).
+package gocherrytest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/vishenosik/gocherry"
+	"github.com/vishenosik/gocherry/pkg/cache"
+	_grpc "github.com/vishenosik/gocherry/pkg/grpc"
+	_http "github.com/vishenosik/gocherry/pkg/http"
+	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/module"
+	"github.com/vishenosik/gocherry/pkg/sql"
+)
+
+const (
+	defaultReadyTimeout = 10 * time.Second
+	defaultStopTimeout  = 10 * time.Second
+)
+
+// Harness is a started app along with the addresses and clients to reach it.
+type Harness struct {
+	App *gocherry.App
+	// BaseURL is the http server address, e.g. http://127.0.0.1:41234, empty unless the app serves http routes
+	BaseURL string
+	// GrpcAddr is the grpc server address, empty unless the app serves grpc services
+	GrpcAddr string
+	// Conn is a client connection to the grpc server, nil unless the app serves grpc services
+	Conn *grpc.ClientConn
+	// Store is the in-memory sqlite store, it's opened and migrated before services are started
+	Store *sql.SqliteStore
+	// Cache is the in-memory cache
+	Cache cache.CacheProvider
+}
+
+type options struct {
+	appOptions     []gocherry.AppOption
+	migrationsFS   fs.FS
+	migrationsPath string
+	readyTimeout   time.Duration
+}
+
+type Option func(*options)
+
+// WithAppOptions sets options the app is created with.
+// Modules set up by the options may look up the *sql.SqliteStore and cache.CacheProvider of the harness.
+func WithAppOptions(opts ...gocherry.AppOption) Option {
+	return func(o *options) {
+		o.appOptions = append(o.appOptions, opts...)
+	}
+}
+
+// WithMigrations runs migrations from fs path on the in-memory store.
+func WithMigrations(fs fs.FS, path string) Option {
+	return func(o *options) {
+		o.migrationsFS = fs
+		o.migrationsPath = path
+	}
+}
+
+// WithReadyTimeout sets how long New waits for the app to get ready, 10s by default.
+func WithReadyTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.readyTimeout = timeout
+		}
+	}
+}
+
+// New creates and starts the app and waits until it's ready, the test fails if it isn't.
+// The app is stopped and the client connection is closed when the test finishes.
+func New(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+
+	o := &options{readyTimeout: defaultReadyTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	log := logs.SetupLoggerConf(logs.Config{Env: logs.EnvTest, Marshaller: "json"})
+
+	store, err := sql.NewSqliteStoreConfig(
+		sql.SqliteConfig{
+			StorePath: fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString()),
+		},
+		sql.WithMigration(o.migrationsFS, o.migrationsPath),
+	)
+	if err != nil {
+		t.Fatalf("gocherrytest: failed to create sqlite store: %v", err)
+	}
+
+	h := &Harness{
+		Store: store,
+		Cache: cache.NewMemoryCache(),
+	}
+
+	httpListener := listen(t)
+	grpcListener := listen(t)
+
+	appOptions := append([]gocherry.AppOption{
+		gocherry.WithLogger(log),
+		gocherry.WithModules(h.module()),
+		gocherry.WithHttpServerOptions(_http.WithListener(httpListener)),
+		gocherry.WithGrpcServerOptions(_grpc.WithListener(grpcListener)),
+	}, o.appOptions...)
+
+	app, err := gocherry.NewApp(appOptions...)
+	if err != nil {
+		httpListener.Close()
+		grpcListener.Close()
+		t.Fatalf("gocherrytest: failed to create app: %v", err)
+	}
+	h.App = app
+
+	var httpServer *_http.Server
+	if app.Lookup(&httpServer) {
+		h.BaseURL = "http://" + httpListener.Addr().String()
+	} else {
+		httpListener.Close()
+	}
+
+	var grpcServer *_grpc.Server
+	if app.Lookup(&grpcServer) {
+		h.GrpcAddr = grpcListener.Addr().String()
+	} else {
+		grpcListener.Close()
+	}
+
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("gocherrytest: failed to start app: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultStopTimeout)
+		defer cancel()
+		if err := app.Stop(ctx); err != nil {
+			t.Errorf("gocherrytest: failed to stop app: %v", err)
+		}
+	})
+
+	select {
+	case <-app.Ready():
+	case <-time.After(o.readyTimeout):
+		t.Fatalf("gocherrytest: app is not ready in %s, services: %+v", o.readyTimeout, app.Services())
+	}
+
+	if h.GrpcAddr != "" {
+		conn, err := grpc.NewClient(h.GrpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("gocherrytest: failed to connect to grpc server: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		h.Conn = conn
+	}
+
+	return h
+}
+
+// module provides the store and the cache to modules of the app.
+func (h *Harness) module() module.Module {
+	return module.New("gocherrytest", nil, func(app module.Registrar) error {
+		app.Provide(h.Store, h.Cache)
+		app.AddServices(h.Store, h.Cache)
+		app.OnStart(func(ctx context.Context) error {
+			_, err := h.Store.Open(ctx)
+			return err
+		})
+		return nil
+	})
+}
+
+func listen(t testing.TB) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("gocherrytest: failed to listen: %v", err)
+	}
+	return listener
+}
@@ -0,0 +1,105 @@
+package gocherrytest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/vishenosik/gocherry"
+	"github.com/vishenosik/gocherry/pkg/cache"
+	"github.com/vishenosik/gocherry/pkg/module"
+	"github.com/vishenosik/gocherry/pkg/sql"
+)
+
+type noopGrpcService struct{}
+
+func (noopGrpcService) RegisterService(*grpc.Server) {}
+
+var migrations = fstest.MapFS{
+	"migrations/00001_users.sql": &fstest.MapFile{Data: []byte(`
+-- +goose Up
+CREATE TABLE users (name TEXT NOT NULL);
+INSERT INTO users (name) VALUES ('cherry');
+-- +goose Down
+DROP TABLE users;
+`)},
+}
+
+func usersModule() gocherry.Module {
+	return module.New("users", nil, func(app module.Registrar) error {
+		var store *sql.SqliteStore
+		var provider cache.CacheProvider
+		if !app.Lookup(&store) || !app.Lookup(&provider) {
+			return errors.New("store or cache is not provided")
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			var name string
+			if err := store.DB().GetContext(r.Context(), &name, "SELECT name FROM users"); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			_ = provider.Set(r.Context(), "last", name, 0)
+			_, _ = io.WriteString(w, name)
+		})
+
+		app.MountHTTP("/users", mux)
+		app.AddGrpcServices(noopGrpcService{})
+		return nil
+	})
+}
+
+func TestHarness(t *testing.T) {
+
+	h := New(t,
+		WithMigrations(migrations, "migrations"),
+		WithAppOptions(gocherry.WithModules(usersModule()), gocherry.WithBuildInfoHeaders()),
+	)
+
+	require.NotEmpty(t, h.BaseURL)
+	require.NotNil(t, h.Conn)
+
+	resp, err := http.Get(h.BaseURL + "/users/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "cherry", string(body))
+	require.Equal(t, gocherry.GetBuildInfo().GitCommit, resp.Header.Get(gocherry.HeaderBuildCommit))
+
+	cached, err := h.Cache.Get(context.Background(), "last")
+	require.NoError(t, err)
+	require.Equal(t, "cherry", cached)
+
+	var header metadata.MD
+	check, err := healthpb.NewHealthClient(h.Conn).Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check.GetStatus())
+	require.Equal(t, []string{gocherry.GetBuildInfo().GitTag}, header.Get(gocherry.HeaderBuildVersion))
+
+	resp, err = http.Get(h.BaseURL + "/readyz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHarnessWithoutServers(t *testing.T) {
+
+	t.Parallel()
+
+	h := New(t)
+
+	require.Empty(t, h.BaseURL)
+	require.Nil(t, h.Conn)
+	require.NoError(t, h.Store.CheckHealth(context.Background()))
+}
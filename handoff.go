@@ -0,0 +1,83 @@
+package gocherry
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/vishenosik/gocherry/pkg/handoff"
+	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/systemd"
+)
+
+const (
+	defaultUpgradeTimeout = 30 * time.Second
+)
+
+// WithListenerHandoff makes http and grpc servers take over listeners inherited from systemd
+// socket activation or from the previous process, listening to the addresses they aren't inherited for.
+// Inherited listeners no server takes over are closed once the app is ready.
+//
+// App.Run upgrades the binary on SIGUSR2: it starts the current executable passing the listeners over,
+// waits until the new process is ready and stops, so connections are served with no downtime.
+func WithListenerHandoff() AppOption {
+	return func(app *App) {
+		h, err := handoff.New()
+		if err != nil {
+			app.initErrs.AppendWrap(err, "failed to inherit listeners")
+			return
+		}
+		if inherited := h.Inherited(); len(inherited) > 0 {
+			app.Log.Info("listeners inherited", slog.Any("listeners", inherited))
+		}
+		app.handoff = h
+	}
+}
+
+// listen listens to the address by the listener handoff if it's enabled.
+func (app *App) listen(network, address string) (net.Listener, error) {
+	if app.handoff != nil {
+		return app.handoff.Listen(network, address)
+	}
+	return net.Listen(network, address)
+}
+
+// handoffReady closes inherited listeners servers haven't taken over
+// and reports the previous process the app is ready, so it stops.
+func (app *App) handoffReady() {
+	if app.handoff == nil {
+		return
+	}
+	closed, err := app.handoff.CloseUnused()
+	if err != nil {
+		app.Log.Error("failed to close unused inherited listeners", logs.Error(err))
+	}
+	if len(closed) > 0 {
+		app.Log.Info("unused inherited listeners closed", slog.Any("listeners", closed))
+	}
+	if err := app.handoff.Ready(); err != nil {
+		app.Log.Error("failed to report readiness to the previous process", logs.Error(err))
+	}
+}
+
+// upgrade starts the upgraded process and waits until it's ready.
+func (app *App) upgrade(ctx context.Context) error {
+
+	app.Log.Info("upgrading app")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultUpgradeTimeout)
+	defer cancel()
+
+	pid, err := app.handoff.Upgrade(ctx)
+	if err != nil {
+		app.Log.Error("upgrade failed, app keeps running", logs.Error(err))
+		return err
+	}
+
+	app.Log.Info("app upgraded, stopping", slog.Int("pid", pid))
+
+	// systemd tracks the upgraded process as the main one, so the unit isn't stopped with this one
+	app.notify(systemd.MainPID(pid))
+	return nil
+}
@@ -0,0 +1,67 @@
+package gocherry
+
+import (
+	"context"
+
+	"github.com/vishenosik/gocherry/pkg/health"
+	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/systemd"
+)
+
+// HealthChecker is optionally implemented by services and closers.
+// Services are checked by both liveness and readiness probes, closers by readiness probe only.
+type HealthChecker = health.Checker
+
+// Health returns the registry aggregating health checks of the app components.
+func (app *App) Health() *health.Registry {
+	return app.health
+}
+
+// Ready returns a channel which is closed once every service of the app is started.
+func (app *App) Ready() <-chan struct{} {
+	return app.ready
+}
+
+func (app *App) registerHealth(components []*component) {
+	for _, comp := range components {
+		if comp.healthRegistered {
+			continue
+		}
+		comp.healthRegistered = true
+
+		checker, ok := comp.value.(HealthChecker)
+		if !ok {
+			continue
+		}
+
+		if comp.service != nil {
+			app.health.AddLiveness(comp.name, checker)
+		} else {
+			app.health.AddReadiness(comp.name, checker)
+		}
+	}
+}
+
+// awaitReady runs after start hooks and marks the app ready once every component is started.
+func (app *App) awaitReady(ctx context.Context, components []*component) {
+	for _, comp := range components {
+		select {
+		case <-comp.ready:
+		case <-app.stopping:
+			return
+		}
+	}
+
+	if err := runHooks(ctx, "after start", app.hooks.afterStart, true); err != nil {
+		app.Log.Error("app is not ready", logs.Error(err))
+		app.fail(err)
+		return
+	}
+
+	app.health.SetReady(true)
+	app.readyOnce.Do(func() { close(app.ready) })
+	app.Log.Info("app is ready")
+
+	app.notify(systemd.Ready, systemd.Status("ready"))
+	app.handoffReady()
+}
@@ -0,0 +1,89 @@
+package gocherry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/vishenosik/gocherry/pkg/errors"
+)
+
+// Hook is a function run at a certain point of the app lifecycle.
+// It receives the context App.Start or App.Stop is called with.
+type Hook func(ctx context.Context) error
+
+type hooks struct {
+	onStart    []Hook
+	afterStart []Hook
+	beforeStop []Hook
+	afterStop  []Hook
+}
+
+// WithOnStart registers hooks run by App.Start before services are started,
+// e.g. to run migrations. A failed hook fails the app start.
+func WithOnStart(hooks ...Hook) AppOption {
+	return func(app *App) {
+		app.hooks.onStart = append(app.hooks.onStart, hooks...)
+	}
+}
+
+// WithAfterStart registers hooks run once every service is started, before the app is marked ready,
+// e.g. to warm caches. A failed hook fails the app like a failed service does.
+func WithAfterStart(hooks ...Hook) AppOption {
+	return func(app *App) {
+		app.hooks.afterStart = append(app.hooks.afterStart, hooks...)
+	}
+}
+
+// WithBeforeStop registers hooks run by App.Stop before the app is drained.
+func WithBeforeStop(hooks ...Hook) AppOption {
+	return func(app *App) {
+		app.hooks.beforeStop = append(app.hooks.beforeStop, hooks...)
+	}
+}
+
+// WithAfterStop registers hooks run by App.Stop once every service is stopped and closer is closed,
+// e.g. to flush metrics.
+func WithAfterStop(hooks ...Hook) AppOption {
+	return func(app *App) {
+		app.hooks.afterStop = append(app.hooks.afterStop, hooks...)
+	}
+}
+
+// runHooks runs hooks one by one. When failFast is set it returns on the first failure,
+// otherwise errors of all hooks are collected.
+func runHooks(ctx context.Context, stage string, hooks []Hook, failFast bool) error {
+
+	result := new(errors.MultiError)
+
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		if err := runHook(ctx, hook); err != nil {
+			result.AppendWrapf(err, "%s hook %s failed", stage, hookName(hook))
+			if failFast {
+				break
+			}
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func runHook(ctx context.Context, hook Hook) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("hook panicked: %v", r)
+		}
+	}()
+	return hook(ctx)
+}
+
+func hookName(hook Hook) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(hook).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
@@ -0,0 +1,224 @@
+package gocherry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vishenosik/gocherry/pkg/config"
+
+	_grpc "github.com/vishenosik/gocherry/pkg/grpc"
+	_http "github.com/vishenosik/gocherry/pkg/http"
+	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/module"
+)
+
+// Module packages config structs, services, closers, http routes and grpc services of a feature.
+type Module = module.Module
+
+type httpRoute struct {
+	prefix  string
+	handler http.Handler
+}
+
+// WithModules sets modules up one by one, so a module may look up values provided by previous ones.
+// Config structs of the modules are listed by App.Configs.
+func WithModules(modules ...Module) AppOption {
+	return func(app *App) {
+		for _, mod := range modules {
+			if mod == nil {
+				continue
+			}
+
+			app.addConfigs(mod.Configs()...)
+
+			if err := mod.Setup(&registrar{app: app}); err != nil {
+				app.initErrs.AppendWrapf(err, "failed to set module %s up", mod.Name())
+				continue
+			}
+
+			app.Log.Debug("module is set up", slog.String("module", mod.Name()))
+		}
+	}
+}
+
+// WithGrpcServices registers services in the app grpc server.
+func WithGrpcServices(services ..._grpc.GrpcService) AppOption {
+	return func(app *App) {
+		app.grpcServices = append(app.grpcServices, services...)
+	}
+}
+
+// WithHttpServerOptions sets options of the http server the app serves its routes with.
+func WithHttpServerOptions(opts ..._http.ServerOption) AppOption {
+	return func(app *App) {
+		app.httpOptions = append(app.httpOptions, opts...)
+	}
+}
+
+// WithGrpcServerOptions sets options of the grpc server the app serves its grpc services with.
+func WithGrpcServerOptions(opts ..._grpc.ServerOption) AppOption {
+	return func(app *App) {
+		app.grpcOptions = append(app.grpcOptions, opts...)
+	}
+}
+
+// ModuleConfigs returns config structs of the modules, e.g. to pass them to ConfigFlags
+// without setting modules up.
+func ModuleConfigs(modules ...Module) []any {
+	configs := config.NewRegistry()
+	for _, mod := range modules {
+		if mod != nil {
+			configs.Add(mod.Configs()...)
+		}
+	}
+	return configs.Structs()
+}
+
+// Configs returns config structs of the components the app is assembled from.
+func (app *App) Configs() []any {
+	return app.configs.Structs()
+}
+
+// WithConfigs registers config structs of the app, so they are checked before the app starts
+// and listed by the admin server. A struct may be registered several times with different prefixes,
+// see config.WithPrefix.
+func WithConfigs(structs ...any) AppOption {
+	return func(app *App) {
+		app.addConfigs(structs...)
+	}
+}
+
+// Provide makes values available through App.Lookup.
+func (app *App) Provide(values ...any) {
+	app.provided = append(app.provided, values...)
+}
+
+// Lookup sets target, a non-nil pointer, to the first provided value assignable to it.
+func (app *App) Lookup(target any) bool {
+
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return false
+	}
+
+	elem := value.Elem()
+
+	for _, provided := range app.provided {
+		if provided == nil {
+			continue
+		}
+		if reflect.TypeOf(provided).AssignableTo(elem.Type()) {
+			elem.Set(reflect.ValueOf(provided))
+			return true
+		}
+	}
+	return false
+}
+
+func (app *App) addConfigs(structs ...any) {
+	app.configs.Add(structs...)
+}
+
+func (app *App) mountHTTP(prefix string, handler http.Handler) {
+	if prefix == "" {
+		prefix = "/"
+	}
+	for _, route := range app.httpRoutes {
+		if route.prefix == prefix {
+			app.initErrs.Append(fmt.Errorf("http routes are mounted to %s twice", prefix))
+			return
+		}
+	}
+	app.addConfigs(_http.ConfigEnv{})
+	app.httpRoutes = append(app.httpRoutes, httpRoute{prefix: prefix, handler: handler})
+}
+
+// buildServers creates http and grpc servers serving routes and services registered by options.
+// Every request gets an ID, see _http.RequestID and _grpc.WithRequestID, and panics of handlers are recovered.
+func (app *App) buildServers() {
+
+	if len(app.httpRoutes) > 0 {
+		recoverOptions := []_http.RecovererOption{_http.WithRecoverLog(app.Log)}
+		for _, hook := range app.panicHooks {
+			recoverOptions = append(recoverOptions, _http.WithPanicHook(hook))
+		}
+
+		router := chi.NewRouter()
+		router.Use(_http.RequestID(), _http.Recoverer(recoverOptions...))
+		app.httpRouter = router
+		app.health.Routers(router)
+		for _, route := range app.httpRoutes {
+			router.Mount(route.prefix, route.handler)
+		}
+
+		server, err := _http.NewHttpServer(
+			router,
+			append([]_http.ServerOption{
+				_http.WithServerLog(app.Log),
+				_http.WithListenFunc(app.listen),
+			}, app.httpOptions...)...,
+		)
+		if err != nil {
+			app.Log.Warn("failed to add http service", logs.Error(err))
+		} else {
+			app.AddService(server)
+			app.Provide(server)
+		}
+	}
+
+	if len(app.grpcServices) > 0 {
+		app.addConfigs(_grpc.ConfigEnv{})
+
+		server, err := _grpc.NewGrpcServer(
+			app.grpcServices,
+			append([]_grpc.ServerOption{
+				_grpc.WithServerLog(app.Log),
+				_grpc.WithRequestID(),
+				_grpc.WithLogInterceptors(),
+				_grpc.WithRecovery(app.panicHooks...),
+				_grpc.WithListenFunc(app.listen),
+			}, app.grpcOptions...)...,
+		)
+		if err != nil {
+			app.Log.Warn("failed to add grpc service", logs.Error(err))
+		} else {
+			app.AddService(server)
+			app.Provide(server)
+		}
+	}
+}
+
+// registrar exposes the app to modules.
+type registrar struct {
+	app *App
+}
+
+func (r *registrar) Logger() *slog.Logger { return r.app.Log }
+
+func (r *registrar) AddServices(services ...any) { r.app.AddServices(services...) }
+
+func (r *registrar) Provide(values ...any) { r.app.Provide(values...) }
+
+func (r *registrar) Lookup(target any) bool { return r.app.Lookup(target) }
+
+func (r *registrar) MountHTTP(prefix string, handler http.Handler) {
+	if handler == nil {
+		r.app.Log.Warn("failed to mount http routes: handler is nil", slog.String("prefix", prefix))
+		return
+	}
+	r.app.mountHTTP(prefix, handler)
+}
+
+func (r *registrar) AddGrpcServices(services ..._grpc.GrpcService) {
+	r.app.grpcServices = append(r.app.grpcServices, services...)
+}
+
+func (r *registrar) OnStart(hooks ...func(ctx context.Context) error) {
+	for _, hook := range hooks {
+		r.app.hooks.onStart = append(r.app.hooks.onStart, hook)
+	}
+}
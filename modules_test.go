@@ -0,0 +1,97 @@
+package gocherry
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/gocherry/pkg/cache"
+	"github.com/vishenosik/gocherry/pkg/config"
+	_http "github.com/vishenosik/gocherry/pkg/http"
+	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/module"
+)
+
+func TestModules(_t *testing.T) {
+
+	t := &T{_t}
+
+	storeModule := func(j *journal) Module {
+		return module.New("store", []any{TestConfig{}}, func(app module.Registrar) error {
+			store := &testCloser{name: "store", journal: j}
+			app.Provide(store, cache.NewNoopCache())
+			app.AddServices(store)
+			app.OnStart(func(context.Context) error {
+				j.add("migrate store")
+				return nil
+			})
+			return nil
+		})
+	}
+
+	routesModule := func() Module {
+		return module.New("routes", nil, func(app module.Registrar) error {
+			var provider cache.CacheProvider
+			if !app.Lookup(&provider) {
+				return stderrors.New("cache is not provided")
+			}
+			app.MountHTTP("/api", http.NotFoundHandler())
+			return nil
+		})
+	}
+
+	t.Run("assemble app", func(t *testing.T) {
+		j := new(journal)
+
+		app, err := NewApp(
+			WithModules(storeModule(j), routesModule()),
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, []any{logs.EnvConfig{}, TestConfig{}, _http.ConfigEnv{}}, app.Configs())
+
+		var store *testCloser
+		require.True(t, app.Lookup(&store))
+		require.Equal(t, "store", store.name)
+
+		services := app.Services()
+		require.Len(t, services, 2)
+		require.Equal(t, "closer", services[0].Type)
+		require.Equal(t, "*http.Server", services[1].Name)
+	})
+
+	t.Run("module order", func(t *testing.T) {
+		j := new(journal)
+
+		_, err := NewApp(
+			WithModules(routesModule(), storeModule(j)),
+		)
+		require.ErrorContains(t, err, "failed to set module routes up")
+	})
+
+	t.Run("config info", func(t *testing.T) {
+		var buf bytes.Buffer
+		modules := []Module{storeModule(new(journal)), storeModule(new(journal))}
+
+		err := parseFlags(&buf, []string{"-config.info"},
+			ConfigFlags(&buf, ModuleConfigs(modules...)...),
+		)
+		require.ErrorIs(t, err, ErrSuccessExit)
+		require.Equal(t, configInfo, buf.String())
+		require.Empty(t, config.Structs())
+	})
+
+	t.Run("prefixed configs", func(t *testing.T) {
+		app, err := NewApp(
+			WithConfigs(TestConfig{}, config.WithPrefix("QUEUE_", TestConfig{})),
+			WithModules(storeModule(new(journal))),
+		)
+		require.NoError(t, err)
+
+		require.Equal(t, []any{logs.EnvConfig{}, TestConfig{}, config.WithPrefix("QUEUE_", TestConfig{})}, app.Configs())
+	})
+}
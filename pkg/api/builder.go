@@ -21,6 +21,11 @@ func ApiV1(routeParts ...string) string {
 	return buildApi(1, routeParts...)
 }
 
+// ApiVersion constructs an API route for the version, e.g. /api/v2/users for ApiVersion(2, "users").
+func ApiVersion(version uint8, routeParts ...string) string {
+	return buildApi(version, routeParts...)
+}
+
 func buildApi(version uint8, routeParts ...string) string {
 	ver := fmt.Sprintf("v%v", version)
 	route := path.Join(prefix, ver)
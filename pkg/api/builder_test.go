@@ -20,6 +20,7 @@ var TestingTable = []struct {
 	{"6", 1, []string{"//test", "non-direct"}, "/api/v1/test/non-direct"},
 	{"7", 1, nil, "/api/v1"},
 	{"8", 1, []string{}, "/api/v1"},
+	{"9", 2, []string{"test"}, "/api/v2/test"},
 }
 
 func Test_BuildApi(t *testing.T) {
@@ -50,3 +51,16 @@ func Test_ApiV1(t *testing.T) {
 		})
 	}
 }
+
+func Test_ApiVersion(t *testing.T) {
+
+	t.Helper()
+	t.Parallel()
+
+	for _, tt := range TestingTable {
+		t.Run(tt.name, func(t *testing.T) {
+			Api := ApiVersion(tt.version, tt.routeParts...)
+			assert.Equal(t, tt.expect, Api)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNotFound is returned by MemoryCache.Get on a cache miss.
+var ErrNotFound = errors.New("cache: key not found")
+
 type CacheProvider interface {
 	Set(ctx context.Context, key string, value any, expiration time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
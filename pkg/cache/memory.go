@@ -0,0 +1,71 @@
+package cache
+
+import (
+	// std
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	//pkg
+)
+
+// MemoryCache keeps values in process memory, it's meant for tests and single instance apps.
+// Values are stored formatted as strings the way redis stores them.
+type MemoryCache struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (item memoryItem) expired(now time.Time) bool {
+	return !item.expiresAt.IsZero() && now.After(item.expiresAt)
+}
+
+func NewMemoryCache() CacheProvider { return newMemoryCache() }
+
+func newMemoryCache() *MemoryCache {
+	return &MemoryCache{items: make(map[string]memoryItem)}
+}
+
+// Set stores the value, zero expiration means the value never expires.
+func (mc *MemoryCache) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
+	item := memoryItem{value: fmt.Sprint(value)}
+	if expiration > 0 {
+		item.expiresAt = time.Now().Add(expiration)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.items[key] = item
+	return nil
+}
+
+// Get returns ErrNotFound when the key is missing or expired.
+func (mc *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	mc.mu.RLock()
+	item, ok := mc.items[key]
+	mc.mu.RUnlock()
+
+	if !ok || item.expired(time.Now()) {
+		return "", ErrNotFound
+	}
+	return item.value, nil
+}
+
+func (mc *MemoryCache) Delete(ctx context.Context, key string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	delete(mc.items, key)
+	return nil
+}
+
+func (mc *MemoryCache) Close(ctx context.Context) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	clear(mc.items)
+	return nil
+}
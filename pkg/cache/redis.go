@@ -7,18 +7,15 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
 	"github.com/vishenosik/gocherry/pkg/config"
+	"github.com/vishenosik/gocherry/pkg/module"
 )
 
-func init() {
-	config.AddStructs(RedisConfigEnv{})
-}
-
 type RedisConfigEnv struct {
 	Host     string        `env:"REDIS_HOST" env-default:"localhost" desc:"Redis server host"`
-	Port     uint16        `env:"REDIS_PORT" env-default:"6380" desc:"Redis server port"`
+	Port     uint16        `env:"REDIS_PORT" env-default:"6380" desc:"Redis server port" validate:"gte=1"`
 	Timeout  time.Duration `env:"REDIS_TIMEOUT" env-default:"15s" desc:"Redis requests timeout"`
 	User     string        `env:"REDIS_USER" env-default:"user" desc:"Redis user"`
-	Password string        `env:"REDIS_USER_PASSWORD" env-default:"password" desc:"Redis user's password"`
+	Password string        `env:"REDIS_PASSWORD" env-deprecated:"REDIS_USER_PASSWORD" desc:"Redis user's password" secret:"true"`
 	DB       int           `env:"REDIS_DB" env-default:"0" desc:"Redis database connection"`
 }
 
@@ -34,10 +31,32 @@ type RedisConfig struct {
 
 type RedisCache struct {
 	client *redis.Client
+	// prefix is the prefix of config variables, see WithRedisPrefix
+	prefix string
 }
 
 type RedisOption func(*RedisCache)
 
+// WithRedisPrefix reads the config from prefixed variables, e.g. CACHE_REDIS_HOST,
+// so one app may connect to several redis servers.
+func WithRedisPrefix(prefix string) RedisOption {
+	return func(rc *RedisCache) {
+		rc.prefix = prefix
+	}
+}
+
+// redisConfig returns conf prefixed if the options say so.
+func redisConfig(conf any, opts ...RedisOption) any {
+	rc := new(RedisCache)
+	for _, opt := range opts {
+		opt(rc)
+	}
+	if rc.prefix != "" {
+		return config.WithPrefix(rc.prefix, conf)
+	}
+	return conf
+}
+
 func validateRedisConfig(config RedisConfig) error {
 	const op = "validateConfig"
 	if err := config.Server.Validate(); err != nil {
@@ -52,7 +71,7 @@ func NewRedisCache(opts ...RedisOption) (CacheProvider, error) {
 
 func newRedisCache(opts ...RedisOption) (*RedisCache, error) {
 	var envConf RedisConfigEnv
-	if err := config.ReadConfigEnv(&envConf); err != nil {
+	if err := config.ReadConfigEnv(redisConfig(&envConf, opts...)); err != nil {
 		return nil, errors.Wrap(err, "setup logger: failed to read config")
 	}
 
@@ -100,6 +119,11 @@ func (rc *RedisCache) Close(_ context.Context) error {
 	return rc.client.Close()
 }
 
+// CheckHealth pings the redis server.
+func (rc *RedisCache) CheckHealth(ctx context.Context) error {
+	return rc.client.Ping(ctx).Err()
+}
+
 func (rc *RedisCache) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
 	return rc.client.Set(ctx, key, value, expiration).Err()
 }
@@ -111,3 +135,17 @@ func (rc *RedisCache) Get(ctx context.Context, key string) (string, error) {
 func (rc *RedisCache) Delete(ctx context.Context, key string) error {
 	return rc.client.Del(ctx, key).Err()
 }
+
+// RedisModule connects to redis when the app is assembled,
+// provides the cache as CacheProvider and closes it when the app stops.
+func RedisModule(opts ...RedisOption) module.Module {
+	return module.New("redis cache", []any{redisConfig(RedisConfigEnv{}, opts...)}, func(app module.Registrar) error {
+		cache, err := newRedisCache(opts...)
+		if err != nil {
+			return errors.Wrap(err, "failed to connect to redis")
+		}
+		app.Provide(cache)
+		app.AddServices(cache)
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Check reads every struct from the current environment and validates it by validate tags
+// and the Validator interface. It returns errors of all missing and invalid variables.
+func Check(structs ...any) error {
+
+	report, err := Preflight(structs...)
+	if err != nil {
+		return err
+	}
+
+	return report.Err()
+}
+
+// readChecked reads the struct of the key from the current environment and validates it.
+func readChecked(key configKey) (any, error) {
+
+	prefixed, conf := key.zero()
+	if err := ReadConfigEnv(prefixed); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key._type, err)
+	}
+
+	if err := validateTags(conf.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key._type, err)
+	}
+
+	if validator, ok := conf.Elem().Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key._type, err)
+		}
+	}
+
+	return conf.Elem().Interface(), nil
+}
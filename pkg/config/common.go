@@ -2,10 +2,10 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Server struct {
@@ -30,9 +30,18 @@ type Credentials struct {
 	Password string
 }
 
-func ReadConfigEnv(conf any) error {
-	if err := cleanenv.ReadConfig(".env", conf); err != nil {
-		return cleanenv.ReadEnv(conf)
-	}
-	return nil
+// String hides the password, so credentials are safe to print.
+func (cred Credentials) String() string {
+	return fmt.Sprintf("%s:%s", cred.User, Redacted)
 }
+
+// LogValue hides the password, so credentials are safe to log.
+func (cred Credentials) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("user", cred.User),
+		slog.String("password", Redacted),
+	)
+}
+
+// EnvFile is the file ReadConfigEnv reads variables from, the environment wins over it.
+const EnvFile = ".env"
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type deprecatedTestConfig struct {
+	Host string `env:"RENAME_TEST_HOST" env-deprecated:"RENAME_TEST_ADDR, OLD_TEST_HOST" env-default:"localhost" desc:"Host"`
+	Pass string `env:"RENAME_TEST_PASS" env-deprecated:"RENAME_TEST_USER_PASS" desc:"Password" secret:"true"`
+}
+
+func TestDeprecatedVariables(_t *testing.T) {
+
+	t := &T{_t}
+
+	t.Run("deprecated read", func(t *testing.T) {
+		t.Setenv("OLD_TEST_HOST", "old")
+		t.Setenv("RENAME_TEST_USER_PASS_FILE", writeFile(t, t.TempDir(), "pass", "s3cr3t\n"))
+
+		var conf deprecatedTestConfig
+		require.NoError(t, ReadConfigEnv(&conf))
+		require.Equal(t, deprecatedTestConfig{Host: "old", Pass: "s3cr3t"}, conf)
+
+		report, err := Preflight(deprecatedTestConfig{})
+		require.NoError(t, err)
+		require.NoError(t, report.Err())
+		require.Empty(t, report.Unknown())
+		require.Equal(t, []Issue{
+			{Kind: IssueDeprecated, Section: "config.deprecatedTestConfig", Env: "OLD_TEST_HOST", Message: "deprecated, use RENAME_TEST_HOST instead"},
+			{Kind: IssueDeprecated, Section: "config.deprecatedTestConfig", Env: "RENAME_TEST_USER_PASS", Message: "deprecated, use RENAME_TEST_PASS instead"},
+		}, report.Deprecated())
+	})
+
+	t.Run("current wins", func(t *testing.T) {
+		t.Setenv("RENAME_TEST_ADDR", "old")
+		t.Setenv("RENAME_TEST_HOST", "new")
+
+		var conf deprecatedTestConfig
+		require.NoError(t, ReadConfigEnv(&conf))
+		require.Equal(t, "new", conf.Host)
+
+		report, err := Preflight(deprecatedTestConfig{})
+		require.NoError(t, err)
+		require.Empty(t, report.Issues)
+	})
+
+	t.Run("variable of another field", func(t *testing.T) {
+		type config struct {
+			Addr string `env:"RENAME_TEST_ADDR"`
+		}
+		t.Setenv("RENAME_TEST_ADDR", "addr")
+
+		report, err := Preflight(deprecatedTestConfig{}, config{})
+		require.NoError(t, err)
+		require.Empty(t, report.Issues)
+	})
+
+	t.Run("prefixed", func(t *testing.T) {
+		t.Setenv("CACHE_RENAME_TEST_ADDR", "cache")
+
+		var conf deprecatedTestConfig
+		require.NoError(t, ReadConfigEnv(WithPrefix("CACHE_", &conf)))
+		require.Equal(t, "cache", conf.Host)
+	})
+
+	t.Run("config info", func(t *testing.T) {
+		var buf bytes.Buffer
+		ConfigInfoEnv(&buf, deprecatedTestConfig{})
+		require.Equal(t, "\n#=== config.deprecatedTestConfig ===#\n\n"+
+			"# Host (string)\nRENAME_TEST_HOST=localhost\n# deprecated: RENAME_TEST_ADDR, OLD_TEST_HOST\n"+
+			"# Password (string, secret, or a file in RENAME_TEST_PASS_FILE)\nRENAME_TEST_PASS=\n# deprecated: RENAME_TEST_USER_PASS\n",
+			buf.String())
+	})
+}
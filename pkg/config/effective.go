@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const Redacted = "******"
+
+// secretMarkers are trailing parts of variable names holding sensitive values
+var secretMarkers = []string{"PASSWORD", "SECRET", "TOKEN", "API_KEY"}
+
+// Value describes an effective value of a config variable and the source it comes from.
+type Value struct {
+	Section string `json:"section" yaml:"section"`
+	Env     string `json:"env" yaml:"env"`
+	Value   string `json:"value" yaml:"value"`
+	// Source is empty if the variable is set by no source and has no default
+	Source Source `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// EffectiveValues reads every struct from all sources at once and returns values
+// of its variables along with their sources. Values of secrets are redacted.
+func EffectiveValues(structs ...any) ([]Value, error) {
+
+	snap, err := loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]Value, 0)
+
+	for _, key := range configKeys(structs) {
+
+		conf, _ := key.zero()
+		sources, err := snap.read(conf)
+		if err != nil {
+			return nil, err
+		}
+
+		section := key.section()
+
+		fields, _ := Fields(conf)
+		for _, field := range fields {
+			val := fmt.Sprint(field.Value.Interface())
+			if field.Secret() {
+				val = Redacted
+			}
+			values = append(values, Value{
+				Section: section,
+				Env:     field.Env,
+				Value:   val,
+				Source:  sources[field.Env],
+			})
+		}
+	}
+
+	return values, nil
+}
+
+// IsSecret reports whether the variable holds sensitive value by its name: it ends with
+// a whole marker part, e.g. DB_PASSWORD or STRIPE_API_KEY, while MAX_TOKENS or CACHE_KEY_PREFIX don't.
+// It's the fallback for fields without the secret tag, see Field.Secret.
+func IsSecret(env string) bool {
+	env = strings.ToUpper(env)
+	for _, marker := range secretMarkers {
+		if env == marker || strings.HasSuffix(env, "_"+marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveValuesEnv writes effective values of the structs in the env file format,
+// grouped by the struct sections. Sources of values follow them in comments,
+// values of secrets are redacted.
+func EffectiveValuesEnv(writer io.Writer, structs ...any) error {
+
+	values, err := EffectiveValues(structs...)
+	if err != nil {
+		return err
+	}
+
+	section := ""
+	for _, value := range values {
+		if value.Section != section {
+			section = value.Section
+			fmt.Fprintf(writer, headerFormat, section)
+		}
+		source := value.Source
+		if source == "" {
+			source = "unset"
+		}
+		fmt.Fprintf(writer, "%s=%s # %s\n", value.Env, value.Value, source)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSeparator = ","
+	secretFileSuffix = "_FILE"
+)
+
+// Field is a config variable of a struct, a field tagged with env.
+type Field struct {
+	reflect.StructField
+	// Value is the settable value of the field
+	Value reflect.Value
+	// Env is the variable of the field, prefixes of the field structs included
+	Env string
+	// path is the field name qualified by names of nested structs, e.g. Cache.Host
+	path string
+	// prefix is the prefix of Env, deprecated variables share it
+	prefix string
+}
+
+// Default returns the env-default tag of the field.
+func (f Field) Default() (string, bool) {
+	return f.Tag.Lookup("env-default")
+}
+
+// Desc returns the desc tag of the field.
+func (f Field) Desc() string {
+	return f.Tag.Get("desc")
+}
+
+// Required reports whether the field is tagged with env-required.
+func (f Field) Required() bool {
+	required, _ := strconv.ParseBool(f.Tag.Get("env-required"))
+	return required
+}
+
+// Secret reports whether the field holds a sensitive value. The secret tag decides it,
+// untagged variables named like secrets, e.g. *_PASSWORD, are secret, see IsSecret.
+// Secrets are redacted in output and may be read from files set by *_FILE variables.
+func (f Field) Secret() bool {
+	if secret, ok := f.Tag.Lookup("secret"); ok {
+		is, _ := strconv.ParseBool(secret)
+		return is
+	}
+	return IsSecret(f.Env)
+}
+
+// FileEnv returns the variable holding a path to the file with the value of the secret field.
+func (f Field) FileEnv() string {
+	return f.Env + secretFileSuffix
+}
+
+// Deprecated returns former variables of the field listed by the env-deprecated tag, comma separated.
+// They are read if the variable is set by no source, so variables may be renamed without breaking deployments.
+func (f Field) Deprecated() []string {
+	tag := f.Tag.Get("env-deprecated")
+	if tag == "" {
+		return nil
+	}
+	deprecated := make([]string, 0)
+	for _, env := range strings.Split(tag, ",") {
+		if env = strings.TrimSpace(env); env != "" {
+			deprecated = append(deprecated, f.prefix+env)
+		}
+	}
+	return deprecated
+}
+
+// Separator returns the env-separator tag splitting list and map items, a comma by default.
+func (f Field) Separator() string {
+	if separator := f.Tag.Get("env-separator"); separator != "" {
+		return separator
+	}
+	return defaultSeparator
+}
+
+// Set parses the raw value into the field.
+func (f Field) Set(raw string) error {
+	if err := setValue(f.Value, raw, f.Separator()); err != nil {
+		return fmt.Errorf("%s: %w", f.Env, err)
+	}
+	return nil
+}
+
+// Fields returns config variables of the struct conf points to, nested structs included.
+// Variables of Prefixed structs and of nested structs tagged with env-prefix are prefixed.
+func Fields(conf any) ([]Field, error) {
+
+	prefix := ""
+	if prefixed, ok := conf.(Prefixed); ok {
+		prefix, conf = prefixed.Prefix, prefixed.Struct
+	}
+
+	value := reflect.ValueOf(conf)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to struct, got %T", conf)
+	}
+
+	fields := make([]Field, 0)
+	walkFields(value.Elem(), prefix, "", func(field Field) {
+		fields = append(fields, field)
+	})
+	return fields, nil
+}
+
+// typeFields returns config variables of a zero struct of the type.
+func typeFields(_type reflect.Type, prefix string) []Field {
+	fields := make([]Field, 0)
+	walkFields(reflect.New(_type).Elem(), prefix, "", func(field Field) {
+		fields = append(fields, field)
+	})
+	return fields
+}
+
+// walkFields visits tagged fields of the struct value, nested structs included.
+// A nested struct tagged with env-prefix appends the tag to the prefix of its variables,
+// so one struct may be nested several times, e.g. Cache and Queue of RedisConfigEnv.
+func walkFields(value reflect.Value, prefix, path string, visit func(Field)) {
+
+	for i := range value.NumField() {
+
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		env, tagged := field.Tag.Lookup("env")
+
+		if !tagged && field.Type.Kind() == reflect.Struct && !isScalar(field.Type) {
+			walkFields(value.Field(i), prefix+field.Tag.Get("env-prefix"), path+field.Name+".", visit)
+			continue
+		}
+
+		if !tagged || env == "" || env == "-" {
+			continue
+		}
+
+		visit(Field{StructField: field, Value: value.Field(i), Env: prefix + env, path: path + field.Name, prefix: prefix})
+	}
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// isScalar reports whether the struct type is parsed from a single value, e.g. time.Time.
+func isScalar(_type reflect.Type) bool {
+	return reflect.PointerTo(_type).Implements(textUnmarshalerType)
+}
+
+func setValue(value reflect.Value, raw, separator string) error {
+
+	if value.CanAddr() {
+		if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(raw))
+		}
+	}
+
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+
+	case reflect.String:
+		value.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+
+	case reflect.Pointer:
+		elem := reflect.New(value.Type().Elem())
+		if err := setValue(elem.Elem(), raw, separator); err != nil {
+			return err
+		}
+		value.Set(elem)
+
+	case reflect.Slice:
+		items := splitList(raw, separator)
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item, separator); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+
+	case reflect.Map:
+		_map := reflect.MakeMap(value.Type())
+		for _, item := range splitList(raw, separator) {
+			key, val, ok := strings.Cut(item, ":")
+			if !ok {
+				return fmt.Errorf("invalid map item %q, key:value expected", item)
+			}
+			k := reflect.New(value.Type().Key()).Elem()
+			if err := setValue(k, strings.TrimSpace(key), separator); err != nil {
+				return err
+			}
+			v := reflect.New(value.Type().Elem()).Elem()
+			if err := setValue(v, strings.TrimSpace(val), separator); err != nil {
+				return err
+			}
+			_map.SetMapIndex(k, v)
+		}
+		value.Set(_map)
+
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
+
+func splitList(raw, separator string) []string {
+	if raw == "" {
+		return nil
+	}
+	items := strings.Split(raw, separator)
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// FlagName returns the command line flag of the variable, e.g. http.port for HTTP_PORT
+// and pool.min-workers for POOL_MIN_WORKERS.
+func FlagName(env string) string {
+	name := strings.ToLower(env)
+	name = strings.Replace(name, "_", ".", 1)
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// BindFlags defines a flag for every variable of the structs documented by a desc tag.
+// Flag values are checked while parsing and win over any other source, see SetFlag.
+// A variable shared by several structs gets one flag.
+func BindFlags(flags *flag.FlagSet, structs ...any) {
+
+	for _, key := range configKeys(structs) {
+
+		for _, field := range key.fields() {
+
+			desc := field.Desc()
+			if desc == "" || desc == "-" {
+				continue
+			}
+
+			name := FlagName(field.Env)
+			if flags.Lookup(name) != nil {
+				continue
+			}
+
+			flags.Var(&fieldFlag{field: field}, name, desc+" ($"+field.Env+")")
+		}
+	}
+}
+
+// fieldFlag is a flag of a config variable.
+type fieldFlag struct {
+	field Field
+	value string
+	set   bool
+}
+
+// String returns the env-default tag, so it's shown as the flag default. Secrets are redacted.
+func (f *fieldFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	if f.field.Secret() {
+		return ""
+	}
+	if f.set {
+		return f.value
+	}
+	value, _ := f.field.Default()
+	return value
+}
+
+func (f *fieldFlag) Set(value string) error {
+
+	// the value is parsed into a scratch one to reject invalid values while parsing flags
+	field := f.field
+	field.Value = reflect.New(field.Type).Elem()
+	if err := field.Set(value); err != nil {
+		return err
+	}
+
+	f.value, f.set = value, true
+	SetFlag(f.field.Env, value)
+	return nil
+}
+
+// IsBoolFlag lets bool flags go without values, e.g. --swagger.enable.
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.field.Type != nil && f.field.Type.Kind() == reflect.Bool
+}
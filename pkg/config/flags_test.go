@@ -0,0 +1,35 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagName(t *testing.T) {
+	require.Equal(t, "env", FlagName("ENV"))
+	require.Equal(t, "http.port", FlagName("HTTP_PORT"))
+	require.Equal(t, "pool.min-workers", FlagName("POOL_MIN_WORKERS"))
+}
+
+func TestBindFlags(t *testing.T) {
+
+	defer ResetLayers()
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	BindFlags(flags, TestConfig{}, &TestConfig{})
+
+	require.NotNil(t, flags.Lookup("verbose"))
+	require.Equal(t, "Greeting", flags.Lookup("greeting").DefValue)
+
+	require.NoError(t, flags.Parse([]string{"-verbose", "-level=5"}))
+
+	var conf TestConfig
+	require.NoError(t, ReadConfigEnv(&conf))
+	require.True(t, conf.Verbose)
+	require.Equal(t, 5, conf.Level)
+}
@@ -8,31 +8,26 @@ import (
 	"strings"
 )
 
-type StructsManager struct {
-	structs []any
-}
-
 var _structs *StructsManager
 
+// Manager returns the global registry of AddStructs.
 func Manager() *StructsManager {
 	if _structs == nil {
-		_structs = &StructsManager{
-			structs: make([]any, 0),
-		}
+		_structs = NewRegistry()
 	}
 	return _structs
 }
 
+// AddStructs registers the structs globally, they are appended to the ones passed explicitly
+// to ConfigInfoEnv and the gocherry flags. Prefer registering structs with the app,
+// the global registry is shared by everything the binary imports.
 func AddStructs(structs ...any) {
-	Manager().structs = append(Manager().structs, structs...)
-}
-
-func (sm *StructsManager) Cleanup() {
-	sm.structs = nil
+	Manager().Add(structs...)
 }
 
+// Structs returns globally registered structs.
 func Structs() []any {
-	return _structs.structs
+	return Manager().Structs()
 }
 
 const (
@@ -44,6 +39,8 @@ type Header interface {
 	Desc() string
 }
 
+// ConfigInfoEnv writes the structs and the registered ones in the env file format.
+// A struct passed several times, e.g. explicitly and by AddStructs, is written once.
 func ConfigInfoEnv(writer io.Writer, structs ...any) {
 
 	structs = append(structs, Structs()...)
@@ -52,13 +49,9 @@ func ConfigInfoEnv(writer io.Writer, structs ...any) {
 		_, _ = writer.Write(fmt.Appendf([]byte{}, headerFormat, header))
 	}
 
-	for _, _struct := range structs {
-		if header, ok := _struct.(Header); ok {
-			writeHeader(header.Desc())
-		} else {
-			writeHeader(reflect.TypeOf(_struct).String())
-		}
-		writer.Write(genEnvConfig(_struct))
+	for _, key := range configKeys(structs) {
+		writeHeader(key.section())
+		writer.Write(genEnvConfig(key))
 	}
 }
 
@@ -91,58 +84,45 @@ func (i *indentWrapper) Bytes() []byte {
 	return []byte(i.writer.String())
 }
 
-func genEnvConfig(cfg any) []byte {
-
-	_type := reflect.TypeOf(cfg)
-
-	if _type.Kind() == reflect.Interface {
-		_type = _type.Elem()
-	}
-
-	if _type.Kind() == reflect.Pointer {
-		_type = _type.Elem()
-	}
-
-	if _type.Kind() != reflect.Struct {
-		return nil
-	}
+func genEnvConfig(key configKey) []byte {
 
 	builder := new(strings.Builder)
 
 	writer := newIndent(builder, 0)
-	genEnvConfigRecursively(builder, _type)
+	genEnvConfigRecursively(builder, key._type, key.prefix)
 
 	return writer.Bytes()
 }
 
-func genEnvConfigRecursively(writer io.Writer, _type reflect.Type) {
-
-	for i := range _type.NumField() {
+// genEnvConfigRecursively writes variables of the struct type, nested structs included.
+// Variables of nested structs tagged with env-prefix are prefixed, see Fields.
+func genEnvConfigRecursively(writer io.Writer, _type reflect.Type, prefix string) {
 
-		field := _type.Field(i)
+	for _, field := range typeFields(_type, prefix) {
 
-		if field.Type.Kind() == reflect.Struct {
-			genEnvConfigRecursively(writer, field.Type)
+		descTag := field.Desc()
+		if descTag == "" || descTag == "-" {
 			continue
 		}
 
-		descTag, ok := field.Tag.Lookup("desc")
-		if !ok || descTag == "-" {
-			continue
-		}
+		// defaults of secrets are placeholders, they aren't worth publishing
+		if field.Secret() {
+			writer.Write(fmt.Appendf([]byte{}, "# %s (%s, secret, or a file in %s)\n", descTag, field.Type, field.FileEnv()))
+			writer.Write([]byte(field.Env + "=\n"))
+		} else {
+			writer.Write(fmt.Appendf([]byte{}, "# %s (%s)\n", descTag, field.Type))
+			writer.Write([]byte(field.Env + "="))
 
-		writer.Write(fmt.Appendf([]byte{}, "# %s (%s)\n", descTag, field.Type))
+			if defaultTag, ok := field.Default(); ok {
+				writer.Write([]byte(defaultTag))
+			}
 
-		if envTag, ok := field.Tag.Lookup("env"); ok {
-			writer.Write([]byte(envTag + "="))
+			writer.Write([]byte("\n"))
 		}
 
-		if defaultTag, ok := field.Tag.Lookup("env-default"); ok {
-			writer.Write([]byte(defaultTag))
+		if deprecated := field.Deprecated(); len(deprecated) > 0 {
+			writer.Write(fmt.Appendf([]byte{}, "# deprecated: %s\n", strings.Join(deprecated, ", ")))
 		}
-
-		writer.Write([]byte("\n"))
-
 	}
 
 }
@@ -39,12 +39,12 @@ func TestConfigInfoEnv(_t *testing.T) {
 
 	t.Run("Combined register", func(t *testing.T) {
 		var buf bytes.Buffer
-		AddStructs(TestConfig{})
+		AddStructs(TestConfig{}, &TestConfig{})
 		ConfigInfoEnv(&buf, TestConfig{})
 
 		fmt.Println(_structs)
 
-		require.Equal(t, configInfoTestCombined, buf.String())
+		require.Equal(t, configInfoTestStraight, buf.String())
 	})
 
 	t.Run("Empty register", func(t *testing.T) {
@@ -75,23 +75,3 @@ GREETING=Greeting
 # Level description (int)
 LEVEL=123
 `
-
-var configInfoTestCombined = `
-#=== Test config ===#
-
-# Verbose description (bool)
-VERBOSE=true
-# Greeting description (string)
-GREETING=Greeting
-# Level description (int)
-LEVEL=123
-
-#=== Test config ===#
-
-# Verbose description (bool)
-VERBOSE=true
-# Greeting description (string)
-GREETING=Greeting
-# Level description (int)
-LEVEL=123
-`
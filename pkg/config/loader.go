@@ -0,0 +1,334 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+// EnvConfigFile is the variable selecting the config file, see SetConfigFile.
+const EnvConfigFile = "CONFIG_FILE"
+
+// Source is a layer a config value comes from.
+type Source string
+
+// Sources from the lowest precedence to the highest one.
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnvFile Source = "env file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	// SourceSecretFile is a file set by a *_FILE variable of any source but flags
+	SourceSecretFile Source = "secret file"
+)
+
+var layers = struct {
+	mu         sync.RWMutex
+	configFile string
+	flags      map[string]string
+}{}
+
+// SetConfigFile selects the YAML, JSON or TOML config file by its extension.
+// The file set by the CONFIG_FILE variable is read unless it's set.
+func SetConfigFile(path string) {
+	layers.mu.Lock()
+	defer layers.mu.Unlock()
+	layers.configFile = path
+}
+
+// ConfigFile returns the selected config file, empty if there's none.
+func ConfigFile() string {
+	layers.mu.RLock()
+	defer layers.mu.RUnlock()
+	if layers.configFile != "" {
+		return layers.configFile
+	}
+	return os.Getenv(EnvConfigFile)
+}
+
+// SetFlag sets the variable value given by a command line flag, it wins over any other source.
+func SetFlag(env, value string) {
+	layers.mu.Lock()
+	defer layers.mu.Unlock()
+	if layers.flags == nil {
+		layers.flags = make(map[string]string)
+	}
+	layers.flags[env] = value
+}
+
+// ResetLayers drops the config file and flag values set by SetConfigFile and SetFlag.
+func ResetLayers() {
+	layers.mu.Lock()
+	defer layers.mu.Unlock()
+	layers.configFile = ""
+	layers.flags = nil
+}
+
+// snapshot holds values of every source read at once,
+// so a struct isn't read from files changing in between.
+type snapshot struct {
+	// file values are strings or lists of strings
+	file    map[string]any
+	envFile map[string]string
+	flags   map[string]string
+}
+
+func loadSnapshot() (*snapshot, error) {
+
+	snap := &snapshot{}
+
+	path := ConfigFile()
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		snap.file = values
+	}
+
+	envFile, err := godotenv.Read(EnvFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", EnvFile, err)
+	}
+	snap.envFile = envFile
+
+	layers.mu.RLock()
+	snap.flags = make(map[string]string, len(layers.flags))
+	for env, value := range layers.flags {
+		snap.flags[env] = value
+	}
+	layers.mu.RUnlock()
+
+	return snap, nil
+}
+
+// lookup returns the value of the field from the source of the highest precedence.
+// Secrets are read from files set by *_FILE variables unless the value is set in the same source.
+// Deprecated variables are read if the variable is set by no source.
+func (snap *snapshot) lookup(field Field) (string, Source, bool, error) {
+	_, value, source, ok, err := snap.lookupName(field)
+	return value, source, ok, err
+}
+
+// lookupName is lookup also returning the variable the value is read from, the field one or a deprecated one.
+func (snap *snapshot) lookupName(field Field) (string, string, Source, bool, error) {
+	for _, env := range append([]string{field.Env}, field.Deprecated()...) {
+		value, source, ok, err := snap.lookupEnv(field, env)
+		if ok || err != nil {
+			return env, value, source, ok, err
+		}
+	}
+	return "", "", "", false, nil
+}
+
+// lookupEnv returns the value of the field set by the variable.
+func (snap *snapshot) lookupEnv(field Field, env string) (string, Source, bool, error) {
+
+	if value, ok := snap.flags[env]; ok {
+		return value, SourceFlag, true, nil
+	}
+
+	sources := []struct {
+		source Source
+		lookup func(env string) (string, bool)
+	}{
+		{SourceEnv, os.LookupEnv},
+		{SourceEnvFile, func(env string) (string, bool) {
+			value, ok := snap.envFile[env]
+			return value, ok
+		}},
+		{SourceFile, func(env string) (string, bool) {
+			switch value := snap.file[env].(type) {
+			case string:
+				return value, true
+			case []string:
+				return strings.Join(value, field.Separator()), true
+			}
+			return "", false
+		}},
+	}
+
+	for _, source := range sources {
+		if value, ok := source.lookup(env); ok {
+			return value, source.source, true, nil
+		}
+		if !field.Secret() {
+			continue
+		}
+		if path, ok := source.lookup(env + secretFileSuffix); ok {
+			value, err := readSecretFile(path)
+			if err != nil {
+				return "", SourceSecretFile, false, fmt.Errorf("%s: %w", env+secretFileSuffix, err)
+			}
+			return value, SourceSecretFile, true, nil
+		}
+	}
+
+	return "", "", false, nil
+}
+
+// readSecretFile reads the secret mounted as a file, the trailing line break is dropped.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// read fills the struct conf points to and returns sources of its variables.
+func (snap *snapshot) read(conf any) (map[string]Source, error) {
+
+	fields, err := Fields(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]Source, len(fields))
+	var errs []error
+
+	for _, field := range fields {
+
+		value, source, ok, err := snap.lookup(field)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			value, ok = field.Default()
+			source = SourceDefault
+		}
+
+		if !ok {
+			if field.Required() {
+				errs = append(errs, fmt.Errorf("%s: required variable is missing", field.Env))
+			}
+			continue
+		}
+
+		if err := field.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%w (from %s)", redactErr(field, err), source))
+			continue
+		}
+		sources[field.Env] = source
+	}
+
+	return sources, errors.Join(errs...)
+}
+
+// ReadConfigEnv fills the struct conf points to from layered sources, later ones winning:
+// env-default tags, the config file, the .env file, the environment and command line flags.
+func ReadConfigEnv(conf any) error {
+	snap, err := loadSnapshot()
+	if err != nil {
+		return err
+	}
+	_, err = snap.read(conf)
+	return err
+}
+
+// readConfigFile reads the config file into variables. Keys of nested maps are joined,
+// so http.port and HTTP_PORT are the same variable. Lists are read as []string.
+func readConfigFile(path string) (map[string]any, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var content any
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &content)
+	case ".json":
+		// numbers are kept as written, e.g. 1000000 isn't turned into 1e+06
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&content)
+	case ".toml":
+		err = toml.Unmarshal(data, &content)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]any)
+	if err := flatten(values, "", content); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(values map[string]any, prefix string, content any) error {
+
+	join := func(key string) string {
+		key = strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+		if prefix == "" {
+			return key
+		}
+		return prefix + "_" + key
+	}
+
+	switch content := content.(type) {
+
+	case nil:
+		if prefix != "" {
+			values[prefix] = ""
+		}
+
+	case map[string]any:
+		for key, value := range content {
+			if err := flatten(values, join(key), value); err != nil {
+				return err
+			}
+		}
+
+	case map[any]any:
+		for key, value := range content {
+			if err := flatten(values, join(fmt.Sprint(key)), value); err != nil {
+				return err
+			}
+		}
+
+	case []any:
+		items := make([]string, 0, len(content))
+		for _, item := range content {
+			switch item.(type) {
+			case map[string]any, map[any]any, []any:
+				return fmt.Errorf("%s: lists of lists or maps aren't supported", prefix)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		values[prefix] = items
+
+	default:
+		if prefix == "" {
+			return errors.New("top level must be a map")
+		}
+		values[prefix] = fmt.Sprint(content)
+	}
+
+	return nil
+}
+
+// redactErr drops the error of parsing the secret, it may quote the value.
+func redactErr(field Field, err error) error {
+	if !field.Secret() {
+		return err
+	}
+	return fmt.Errorf("%s: invalid value of %s", field.Env, field.Type)
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type loaderTestConfig struct {
+	Host    string        `env:"LOADER_TEST_HOST" env-default:"localhost"`
+	Port    uint16        `env:"LOADER_TEST_PORT" env-default:"8080"`
+	Timeout time.Duration `env:"LOADER_TEST_TIMEOUT" env-default:"15s"`
+	Debug   bool          `env:"LOADER_TEST_DEBUG"`
+	Nested  struct {
+		Tags   []string       `env:"LOADER_TEST_TAGS" env-separator:";"`
+		Limits map[string]int `env:"LOADER_TEST_LIMITS"`
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestReadConfigEnvLayers(t *testing.T) {
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Cleanup(ResetLayers)
+
+	read := func(t *testing.T) loaderTestConfig {
+		var conf loaderTestConfig
+		require.NoError(t, ReadConfigEnv(&conf))
+		return conf
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		conf := read(t)
+		require.Equal(t, "localhost", conf.Host)
+		require.Equal(t, uint16(8080), conf.Port)
+		require.Equal(t, 15*time.Second, conf.Timeout)
+		require.False(t, conf.Debug)
+	})
+
+	SetConfigFile(writeFile(t, dir, "app.yaml", `
+loader_test:
+  host: file.host
+  port: 9000
+  debug: true
+  tags: [a, b]
+LOADER_TEST_TIMEOUT: 1m
+`))
+
+	t.Run("config file", func(t *testing.T) {
+		conf := read(t)
+		require.Equal(t, "file.host", conf.Host)
+		require.Equal(t, uint16(9000), conf.Port)
+		require.Equal(t, time.Minute, conf.Timeout)
+		require.True(t, conf.Debug)
+		require.Equal(t, []string{"a", "b"}, conf.Nested.Tags)
+	})
+
+	writeFile(t, dir, EnvFile, "LOADER_TEST_HOST=envfile.host\nLOADER_TEST_PORT=9001\n")
+
+	t.Run("env file wins over config file", func(t *testing.T) {
+		conf := read(t)
+		require.Equal(t, "envfile.host", conf.Host)
+		require.Equal(t, uint16(9001), conf.Port)
+		require.Equal(t, time.Minute, conf.Timeout)
+	})
+
+	t.Run("env wins over env file", func(t *testing.T) {
+		t.Setenv("LOADER_TEST_HOST", "env.host")
+		conf := read(t)
+		require.Equal(t, "env.host", conf.Host)
+		require.Equal(t, uint16(9001), conf.Port)
+	})
+
+	t.Run("sources", func(t *testing.T) {
+		t.Setenv("LOADER_TEST_DEBUG", "false")
+		SetFlag("LOADER_TEST_LIMITS", "cpu:1")
+		defer ResetLayers()
+		SetConfigFile(filepath.Join(dir, "app.yaml"))
+
+		values, err := EffectiveValues(loaderTestConfig{})
+		require.NoError(t, err)
+
+		sources := make(map[string]Source)
+		for _, value := range values {
+			sources[value.Env] = value.Source
+		}
+		require.Equal(t, map[string]Source{
+			"LOADER_TEST_HOST":    SourceEnvFile,
+			"LOADER_TEST_PORT":    SourceEnvFile,
+			"LOADER_TEST_TIMEOUT": SourceFile,
+			"LOADER_TEST_DEBUG":   SourceEnv,
+			"LOADER_TEST_TAGS":    SourceFile,
+			"LOADER_TEST_LIMITS":  SourceFlag,
+		}, sources)
+	})
+
+	t.Run("flag wins over env", func(t *testing.T) {
+		t.Setenv("LOADER_TEST_HOST", "env.host")
+		SetFlag("LOADER_TEST_HOST", "flag.host")
+		defer ResetLayers()
+		conf := read(t)
+		require.Equal(t, "flag.host", conf.Host)
+	})
+
+	t.Run("config file from env", func(t *testing.T) {
+		SetConfigFile("")
+		t.Setenv(EnvConfigFile, writeFile(t, dir, "app.json", `{"LOADER_TEST_TIMEOUT": "2m"}`))
+		conf := read(t)
+		require.Equal(t, 2*time.Minute, conf.Timeout)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("LOADER_TEST_PORT", "port")
+		var conf loaderTestConfig
+		require.ErrorContains(t, ReadConfigEnv(&conf), "LOADER_TEST_PORT")
+	})
+
+	t.Run("missing config file", func(t *testing.T) {
+		SetConfigFile(filepath.Join(dir, "missing.yaml"))
+		defer ResetLayers()
+		var conf loaderTestConfig
+		require.ErrorIs(t, ReadConfigEnv(&conf), os.ErrNotExist)
+	})
+}
+
+func TestReadConfigFile(t *testing.T) {
+
+	dir := t.TempDir()
+
+	want := map[string]any{
+		"LOADER_TEST_HOST":   "example.com",
+		"LOADER_TEST_PORT":   "1000000",
+		"LOADER_TEST_TAGS":   []string{"a", "b"},
+		"LOADER_TEST_LIMITS": "cpu:2",
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "app.yml", `
+loader-test:
+  host: example.com
+  port: 1000000
+  tags: [a, b]
+LOADER_TEST_LIMITS: "cpu:2"
+`},
+		{"json", "app.json", `{
+  "loader_test": {"host": "example.com", "port": 1000000, "tags": ["a", "b"]},
+  "LOADER_TEST_LIMITS": "cpu:2"
+}`},
+		{"toml", "app.toml", `
+LOADER_TEST_LIMITS = "cpu:2"
+
+[loader_test]
+host = "example.com"
+port = 1000000
+tags = ["a", "b"]
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := readConfigFile(writeFile(t, dir, tt.file, tt.content))
+			require.NoError(t, err)
+			require.Equal(t, want, values)
+		})
+	}
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := readConfigFile(writeFile(t, dir, "app.ini", "a=b"))
+		require.ErrorContains(t, err, "unsupported config file format")
+	})
+}
+
+func TestFieldSet(t *testing.T) {
+
+	var conf loaderTestConfig
+	fields, err := Fields(&conf)
+	require.NoError(t, err)
+	require.Len(t, fields, 6)
+
+	for _, field := range fields {
+		switch field.Env {
+		case "LOADER_TEST_TAGS":
+			require.NoError(t, field.Set("a; b"))
+		case "LOADER_TEST_LIMITS":
+			require.NoError(t, field.Set("cpu:2, mem:512"))
+		}
+	}
+
+	require.Equal(t, []string{"a", "b"}, conf.Nested.Tags)
+	require.Equal(t, map[string]int{"cpu": 2, "mem": 512}, conf.Nested.Limits)
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// IssueKind is a kind of config problem found by Preflight.
+type IssueKind string
+
+const (
+	// IssueMissing is a required variable set by no source
+	IssueMissing IssueKind = "missing"
+	// IssueInvalid is a variable which can't be parsed or fails validation
+	IssueInvalid IssueKind = "invalid"
+	// IssueUnknown is a variable with a registered prefix matching no field, e.g. a typo
+	IssueUnknown IssueKind = "unknown"
+	// IssueDeprecated is a deprecated variable read instead of the field one, see Field.Deprecated
+	IssueDeprecated IssueKind = "deprecated"
+)
+
+// Issue is a config problem found by Preflight.
+type Issue struct {
+	Kind    IssueKind `json:"kind" yaml:"kind"`
+	Section string    `json:"section,omitempty" yaml:"section,omitempty"`
+	// Env is empty for problems of a whole struct, e.g. failed Validator
+	Env     string `json:"env,omitempty" yaml:"env,omitempty"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (issue Issue) Error() string {
+	if issue.Env == "" {
+		return fmt.Sprintf("%s %s: %s", issue.Kind, issue.Section, issue.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", issue.Kind, issue.Env, issue.Message)
+}
+
+// Report lists config problems found by Preflight.
+type Report struct {
+	Issues []Issue
+}
+
+// Err joins missing and invalid variables, unknown and deprecated ones are only worth a warning.
+func (report *Report) Err() error {
+	var errs []error
+	for _, issue := range report.Issues {
+		if issue.Kind == IssueMissing || issue.Kind == IssueInvalid {
+			errs = append(errs, issue)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Unknown returns unknown variables.
+func (report *Report) Unknown() []string {
+	unknown := make([]string, 0)
+	for _, issue := range report.Issues {
+		if issue.Kind == IssueUnknown {
+			unknown = append(unknown, issue.Env)
+		}
+	}
+	return unknown
+}
+
+// Deprecated returns issues of deprecated variables which are set.
+func (report *Report) Deprecated() []Issue {
+	deprecated := make([]Issue, 0)
+	for _, issue := range report.Issues {
+		if issue.Kind == IssueDeprecated {
+			deprecated = append(deprecated, issue)
+		}
+	}
+	return deprecated
+}
+
+// Write writes the report as a table, one issue per line.
+func (report *Report) Write(writer io.Writer) {
+
+	if len(report.Issues) == 0 {
+		fmt.Fprintln(writer, "config is valid")
+		return
+	}
+
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	for _, issue := range report.Issues {
+		env := issue.Env
+		if env == "" {
+			env = "-"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", issue.Kind, env, issue.Section, issue.Message)
+	}
+	table.Flush()
+}
+
+var valid = newValidator()
+
+// newValidator returns a validator naming fields by their variables.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("env")
+	})
+	return v
+}
+
+// Preflight reads every struct from all sources at once and reports variables which are missing,
+// fail parsing, validate tags or the Validator of the struct, along with unknown and deprecated variables.
+// Unknown variables share the prefix of a field, e.g. HTTP_PROT for HTTP_PORT, but match none.
+// Well-known variables of other programs sharing prefixes, e.g. HTTP_PROXY or GRPC_TRACE, aren't reported.
+// Deprecated variables are reported if they are read, unless they are variables of other fields.
+// An error is returned if a source can't be read.
+func Preflight(structs ...any) (*Report, error) {
+
+	snap, err := loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	report := new(Report)
+	known := make(map[string]bool)
+	current := make(map[string]bool)
+	deprecated := make([]Issue, 0)
+
+	for _, key := range configKeys(structs) {
+
+		prefixed, conf := key.zero()
+		section := key.section()
+
+		fields, _ := Fields(prefixed)
+		failed := make(map[string]bool)
+		secrets := make(map[string]bool)
+		// validator names fields by their env tags, prefixes are lost
+		envs := make(map[string]string)
+
+		for _, field := range fields {
+			current[field.Env] = true
+			envs[field.path] = field.Env
+			for _, env := range append([]string{field.Env}, field.Deprecated()...) {
+				known[env] = true
+				if field.Secret() {
+					known[env+secretFileSuffix] = true
+				}
+			}
+			if field.Secret() {
+				secrets[field.Env] = true
+			}
+
+			env, value, source, ok, err := snap.lookupName(field)
+			if ok && env != field.Env {
+				deprecated = append(deprecated, Issue{
+					Kind:    IssueDeprecated,
+					Section: section,
+					Env:     env,
+					Message: fmt.Sprintf("deprecated, use %s instead", field.Env),
+				})
+			}
+			if err != nil {
+				report.add(IssueInvalid, section, field.Env, errors.Unwrap(err).Error())
+				failed[field.Env] = true
+				continue
+			}
+			if !ok {
+				value, ok = field.Default()
+				source = SourceDefault
+			}
+
+			if !ok {
+				if field.Required() {
+					report.add(IssueMissing, section, field.Env, "required variable is not set")
+					failed[field.Env] = true
+				}
+				continue
+			}
+
+			if err := field.Set(value); err != nil {
+				report.add(IssueInvalid, section, field.Env, fmt.Sprintf("%s (from %s)", errors.Unwrap(redactErr(field, err)), source))
+				failed[field.Env] = true
+			}
+		}
+
+		if len(failed) > 0 {
+			// the struct isn't filled completely, so validation makes no sense
+			continue
+		}
+
+		if err := validateTags(conf.Interface()); err != nil {
+			var fieldErrs validator.ValidationErrors
+			if !errors.As(err, &fieldErrs) {
+				report.add(IssueInvalid, section, "", err.Error())
+				continue
+			}
+			for _, fieldErr := range fieldErrs {
+				kind := IssueInvalid
+				if fieldErr.Tag() == "required" {
+					kind = IssueMissing
+				}
+				env := fieldErr.Field()
+				// the namespace starts with the struct type name, e.g. RedisConfigEnv.Host
+				if _, path, ok := strings.Cut(fieldErr.StructNamespace(), "."); ok && envs[path] != "" {
+					env = envs[path]
+				}
+				report.add(kind, section, env, describeFieldError(fieldErr, secrets[env]))
+			}
+			continue
+		}
+
+		if validator, ok := conf.Elem().Interface().(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				report.add(IssueInvalid, section, "", err.Error())
+			}
+		}
+	}
+
+	for _, issue := range deprecated {
+		// e.g. a variable moved to another struct isn't deprecated there
+		if !current[issue.Env] {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	for _, env := range snap.unknown(known) {
+		report.add(IssueUnknown, "", env, "matches no config variable")
+	}
+
+	return report, nil
+}
+
+func (report *Report) add(kind IssueKind, section, env, message string) {
+	report.Issues = append(report.Issues, Issue{
+		Kind:    kind,
+		Section: section,
+		Env:     env,
+		Message: message,
+	})
+}
+
+// validateTags checks validate tags of the struct conf points to.
+func validateTags(conf any) error {
+	return valid.Struct(conf)
+}
+
+// describeFieldError tells the failed rule and the value unless it's secret.
+func describeFieldError(fieldErr validator.FieldError, secret bool) string {
+	rule := fieldErr.Tag()
+	if fieldErr.Param() != "" {
+		rule += "=" + fieldErr.Param()
+	}
+	if secret {
+		return fmt.Sprintf("must satisfy %s", rule)
+	}
+	return fmt.Sprintf("must satisfy %s, got %q", rule, fmt.Sprint(fieldErr.Value()))
+}
+
+// wellKnownEnvs are variables of proxies and libraries which share prefixes with config variables,
+// names ending with * match prefixes.
+var wellKnownEnvs = []string{
+	"HTTP_PROXY",
+	"HTTPS_PROXY",
+	"NO_PROXY",
+	"GRPC_GO_*",
+	"GRPC_TRACE",
+	"GRPC_VERBOSITY",
+	"GRPC_DEFAULT_SSL_ROOTS_FILE_PATH",
+	"GRPC_ENFORCE_ALPN_ENABLED",
+	"GRPC_XDS_*",
+}
+
+// wellKnown reports whether the variable is one of wellKnownEnvs.
+func wellKnown(env string) bool {
+	for _, known := range wellKnownEnvs {
+		if prefix, ok := strings.CutSuffix(known, "*"); ok && strings.HasPrefix(env, prefix) || env == known {
+			return true
+		}
+	}
+	return false
+}
+
+// unknown returns variables of every source sharing a prefix with known ones but matching none.
+// Prefixes are known variables without their last part, e.g. HTTP_ for HTTP_PORT and REDIS_POOL_ for REDIS_POOL_SIZE.
+func (snap *snapshot) unknown(known map[string]bool) []string {
+
+	prefixes := make(map[string]bool)
+	for env := range known {
+		if i := strings.LastIndex(env, "_"); i > 0 {
+			prefixes[env[:i+1]] = true
+		}
+	}
+
+	candidates := make(map[string]bool)
+	for _, variable := range os.Environ() {
+		env, _, _ := strings.Cut(variable, "=")
+		candidates[env] = true
+	}
+	for _, source := range []map[string]string{snap.envFile, snap.flags} {
+		for env := range source {
+			candidates[env] = true
+		}
+	}
+	for env := range snap.file {
+		candidates[env] = true
+	}
+
+	unknown := make([]string, 0)
+	for env := range candidates {
+		if known[env] || wellKnown(env) {
+			continue
+		}
+		for prefix := range prefixes {
+			if strings.HasPrefix(env, prefix) {
+				unknown = append(unknown, env)
+				break
+			}
+		}
+	}
+
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type preflightTestConfig struct {
+	Host string `env:"PREFLIGHT_HOST" env-required:"true"`
+	Port int    `env:"PREFLIGHT_PORT" env-default:"8080" validate:"gte=1,lte=65535"`
+	Mode string `env:"PREFLIGHT_MODE" env-default:"fast" validate:"oneof=fast slow"`
+}
+
+func (preflightTestConfig) Desc() string {
+	return "Preflight test config"
+}
+
+func TestPreflight(t *testing.T) {
+
+	t.Run("missing", func(t *testing.T) {
+		report, err := Preflight(preflightTestConfig{})
+		require.NoError(t, err)
+		require.Equal(t, []Issue{{
+			Kind:    IssueMissing,
+			Section: "Preflight test config",
+			Env:     "PREFLIGHT_HOST",
+			Message: "required variable is not set",
+		}}, report.Issues)
+		require.ErrorContains(t, report.Err(), "missing PREFLIGHT_HOST")
+	})
+
+	t.Run("unparsable", func(t *testing.T) {
+		t.Setenv("PREFLIGHT_HOST", "localhost")
+		t.Setenv("PREFLIGHT_PORT", "http")
+		report, err := Preflight(preflightTestConfig{})
+		require.NoError(t, err)
+		require.Len(t, report.Issues, 1)
+		require.Equal(t, IssueInvalid, report.Issues[0].Kind)
+		require.Equal(t, "PREFLIGHT_PORT", report.Issues[0].Env)
+		require.Contains(t, report.Issues[0].Message, "(from env)")
+	})
+
+	t.Run("validate tags", func(t *testing.T) {
+		t.Setenv("PREFLIGHT_HOST", "localhost")
+		t.Setenv("PREFLIGHT_PORT", "0")
+		t.Setenv("PREFLIGHT_MODE", "sideways")
+		report, err := Preflight(preflightTestConfig{})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		report.Write(&buf)
+		require.Equal(t, ""+
+			"invalid  PREFLIGHT_PORT  Preflight test config  must satisfy gte=1, got \"0\"\n"+
+			"invalid  PREFLIGHT_MODE  Preflight test config  must satisfy oneof=fast slow, got \"sideways\"\n",
+			buf.String())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		writeFile(t, ".", EnvFile, "PREFLIGHT_PROT=9000\n")
+		t.Setenv("PREFLIGHT_HOST", "localhost")
+		report, err := Preflight(preflightTestConfig{}, &preflightTestConfig{})
+		require.NoError(t, err)
+		require.NoError(t, report.Err())
+		require.Equal(t, []string{"PREFLIGHT_PROT"}, report.Unknown())
+	})
+
+	t.Run("unknown in environment", func(t *testing.T) {
+		t.Setenv("HTTP_PROT", "8080")
+		t.Setenv("HTTP_PROXY", "http://proxy:3128")
+		t.Setenv("GRPC_TRACE", "all")
+		t.Setenv("GRPC_GO_LOG_SEVERITY_LEVEL", "info")
+		report, err := Preflight(struct {
+			Port uint16 `env:"HTTP_PORT"`
+			Grpc uint16 `env:"GRPC_PORT"`
+		}{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"HTTP_PROT"}, report.Unknown())
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		t.Setenv("PREFLIGHT_HOST", "localhost")
+		report, err := Preflight(preflightTestConfig{})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		report.Write(&buf)
+		require.Equal(t, "config is valid\n", buf.String())
+	})
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Prefixed is a config struct whose variables are prefixed, e.g. CACHE_REDIS_HOST for REDIS_HOST.
+// It's accepted wherever config structs are, so one struct may be read several times
+// with different prefixes, see WithPrefix.
+type Prefixed struct {
+	Prefix string
+	Struct any
+}
+
+// WithPrefix prefixes variables of the config struct, conf is a struct or a pointer to one.
+//
+//	var conf RedisConfigEnv
+//	err := config.ReadConfigEnv(config.WithPrefix("CACHE_", &conf))
+func WithPrefix(prefix string, conf any) Prefixed {
+	return Prefixed{Prefix: prefix, Struct: conf}
+}
+
+// Registry is a set of config structs, e.g. of the components an app is assembled from.
+// Structs are deduplicated by their types and prefixes.
+type Registry struct {
+	mu      sync.Mutex
+	structs []any
+}
+
+// StructsManager is the registry of AddStructs.
+type StructsManager = Registry
+
+// NewRegistry returns a registry of the structs.
+func NewRegistry(structs ...any) *Registry {
+	r := new(Registry)
+	r.Add(structs...)
+	return r
+}
+
+// Add registers the structs skipping ones of registered types and prefixes.
+func (r *Registry) Add(structs ...any) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, _struct := range structs {
+		key, ok := configKeyOf(_struct)
+		if !ok {
+			continue
+		}
+		duplicate := false
+		for _, registered := range r.structs {
+			if other, _ := configKeyOf(registered); other == key {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			r.structs = append(r.structs, _struct)
+		}
+	}
+}
+
+// AddPrefixed registers the structs with the prefix, see WithPrefix.
+func (r *Registry) AddPrefixed(prefix string, structs ...any) {
+	for _, _struct := range structs {
+		r.Add(WithPrefix(prefix, _struct))
+	}
+}
+
+// Structs returns registered structs in the order they are added.
+func (r *Registry) Structs() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]any(nil), r.structs...)
+}
+
+// prefixOf returns the prefix the struct type is registered with, if it's registered once.
+func (r *Registry) prefixOf(_type reflect.Type) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefix, found := "", 0
+	for _, _struct := range r.structs {
+		if key, _ := configKeyOf(_struct); key._type == _type {
+			prefix = key.prefix
+			found++
+		}
+	}
+	return prefix, found == 1
+}
+
+// Cleanup drops registered structs.
+func (r *Registry) Cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.structs = nil
+}
+
+// configKey identifies a config struct, the same struct with different prefixes holds different variables.
+type configKey struct {
+	_type  reflect.Type
+	prefix string
+}
+
+// configKeyOf returns the struct type of the config, a struct, a pointer to one or Prefixed,
+// and the prefix of its variables.
+func configKeyOf(conf any) (configKey, bool) {
+
+	prefix := ""
+	if prefixed, ok := conf.(Prefixed); ok {
+		prefix, conf = prefixed.Prefix, prefixed.Struct
+	}
+
+	_type := reflect.TypeOf(conf)
+	if _type == nil {
+		return configKey{}, false
+	}
+	if _type.Kind() == reflect.Pointer {
+		_type = _type.Elem()
+	}
+	if _type.Kind() != reflect.Struct {
+		return configKey{}, false
+	}
+
+	return configKey{_type: _type, prefix: prefix}, true
+}
+
+// configKeys returns keys of the structs dropping duplicates and values which aren't structs.
+func configKeys(structs []any) []configKey {
+	keys := make([]configKey, 0, len(structs))
+	seen := make(map[configKey]bool)
+	for _, _struct := range structs {
+		key, ok := configKeyOf(_struct)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// section returns the description of the struct or its type, followed by the prefix if any.
+func (key configKey) section() string {
+	section := key._type.String()
+	if header, ok := reflect.New(key._type).Interface().(Header); ok {
+		section = header.Desc()
+	}
+	if key.prefix != "" {
+		section += " (" + key.prefix + "*)"
+	}
+	return section
+}
+
+// zero returns a pointer to a zero struct of the key, prefixed if needed, and the pointer itself.
+func (key configKey) zero() (any, reflect.Value) {
+	conf := reflect.New(key._type)
+	if key.prefix != "" {
+		return WithPrefix(key.prefix, conf.Interface()), conf
+	}
+	return conf.Interface(), conf
+}
+
+// fields returns config variables of a zero struct of the key.
+func (key configKey) fields() []Field {
+	return typeFields(key._type, key.prefix)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type registryTestConfig struct {
+	Host string `env:"STORE_HOST" env-default:"localhost" desc:"Store host"`
+	Port uint16 `env:"STORE_PORT" env-default:"6380" desc:"Store port" validate:"gte=1"`
+}
+
+func (registryTestConfig) Desc() string {
+	return "Store settings"
+}
+
+type registryTestNested struct {
+	Cache registryTestConfig `env-prefix:"CACHE_"`
+	Queue registryTestConfig `env-prefix:"QUEUE_"`
+}
+
+func TestRegistry(_t *testing.T) {
+
+	t := &T{_t}
+
+	t.Run("dedup", func(t *testing.T) {
+		registry := NewRegistry(registryTestConfig{}, &registryTestConfig{}, 42, nil)
+		registry.AddPrefixed("CACHE_", registryTestConfig{})
+		registry.Add(WithPrefix("CACHE_", &registryTestConfig{}), WithPrefix("QUEUE_", registryTestConfig{}))
+
+		require.Equal(t, []any{
+			registryTestConfig{},
+			WithPrefix("CACHE_", registryTestConfig{}),
+			WithPrefix("QUEUE_", registryTestConfig{}),
+		}, registry.Structs())
+
+		registry.Cleanup()
+		require.Empty(t, registry.Structs())
+	})
+
+	t.Run("prefixed read", func(t *testing.T) {
+		t.Setenv("CACHE_STORE_HOST", "cache")
+		t.Setenv("QUEUE_STORE_PORT", "6390")
+
+		var cache, queue registryTestConfig
+		require.NoError(t, ReadConfigEnv(WithPrefix("CACHE_", &cache)))
+		require.NoError(t, ReadConfigEnv(WithPrefix("QUEUE_", &queue)))
+
+		require.Equal(t, registryTestConfig{Host: "cache", Port: 6380}, cache)
+		require.Equal(t, registryTestConfig{Host: "localhost", Port: 6390}, queue)
+	})
+
+	t.Run("nested prefixes", func(t *testing.T) {
+		t.Setenv("CACHE_STORE_HOST", "cache")
+		t.Setenv("QUEUE_STORE_HOST", "queue")
+
+		var conf registryTestNested
+		require.NoError(t, ReadConfigEnv(&conf))
+		require.Equal(t, "cache", conf.Cache.Host)
+		require.Equal(t, "queue", conf.Queue.Host)
+
+		var buf bytes.Buffer
+		ConfigInfoEnv(&buf, WithPrefix("APP_", registryTestNested{}))
+		require.Equal(t, "\n#=== config.registryTestNested (APP_*) ===#\n\n"+
+			"# Store host (string)\nAPP_CACHE_STORE_HOST=localhost\n# Store port (uint16)\nAPP_CACHE_STORE_PORT=6380\n"+
+			"# Store host (string)\nAPP_QUEUE_STORE_HOST=localhost\n# Store port (uint16)\nAPP_QUEUE_STORE_PORT=6380\n",
+			buf.String())
+	})
+
+	t.Run("config info", func(t *testing.T) {
+		var buf bytes.Buffer
+		ConfigInfoEnv(&buf, WithPrefix("CACHE_", registryTestConfig{}), WithPrefix("QUEUE_", registryTestConfig{}))
+		require.Equal(t, "\n#=== Store settings (CACHE_*) ===#\n\n"+
+			"# Store host (string)\nCACHE_STORE_HOST=localhost\n# Store port (uint16)\nCACHE_STORE_PORT=6380\n"+
+			"\n#=== Store settings (QUEUE_*) ===#\n\n"+
+			"# Store host (string)\nQUEUE_STORE_HOST=localhost\n# Store port (uint16)\nQUEUE_STORE_PORT=6380\n",
+			buf.String())
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		writeFile(t, ".", EnvFile, "QUEUE_STORE_PROT=6390\n")
+		t.Setenv("QUEUE_STORE_PORT", "0")
+
+		report, err := Preflight(registryTestNested{})
+		require.NoError(t, err)
+		require.Equal(t, []Issue{
+			{Kind: IssueInvalid, Section: "config.registryTestNested", Env: "QUEUE_STORE_PORT", Message: `must satisfy gte=1, got "0"`},
+			{Kind: IssueUnknown, Env: "QUEUE_STORE_PROT", Message: "matches no config variable"},
+		}, report.Issues)
+	})
+}
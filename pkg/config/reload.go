@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Reloadable is implemented by components applying config changes without restart.
+type Reloadable interface {
+	// ReloadConfig returns a value of the config struct type the component is configured with,
+	// it may be prefixed by WithPrefix.
+	ReloadConfig() any
+	// Reload applies the config re-read from the environment, it receives a value of the
+	// ReloadConfig type. The config is validated before it's handed over.
+	Reload(conf any) error
+}
+
+// Validator is optionally implemented by config structs to reject invalid values on reload.
+type Validator interface {
+	Validate() error
+}
+
+// Reloader re-reads config structs and hands them to components registered for them.
+type Reloader struct {
+	mu       sync.Mutex
+	entries  []*reloadEntry
+	registry *Registry
+}
+
+type reloadEntry struct {
+	key configKey
+	// current is the config read with currentKey, the prefix may be registered after the entry
+	current    any
+	currentKey configKey
+	apply      []func(conf any) error
+}
+
+type ReloaderOption func(*Reloader)
+
+// WithRegistry makes the reloader read structs registered without prefixes with the prefixes
+// they have in the registry, e.g. the app configs, so prefixed variables are reloaded.
+// Structs the registry holds with several prefixes are read as they are registered.
+func WithRegistry(registry *Registry) ReloaderOption {
+	return func(r *Reloader) {
+		r.registry = registry
+	}
+}
+
+func NewReloader(opts ...ReloaderOption) *Reloader {
+	r := &Reloader{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Add registers the components for the config structs they return by ReloadConfig.
+func (r *Reloader) Add(components ...Reloadable) {
+	for _, component := range components {
+		if component == nil {
+			continue
+		}
+		r.register(component.ReloadConfig(), component.Reload)
+	}
+}
+
+// OnReload registers apply for config structs of type T, see WithRegistry for prefixes.
+func OnReload[T any](r *Reloader, apply func(conf T) error) {
+	var zero T
+	r.register(zero, func(conf any) error {
+		return apply(conf.(T))
+	})
+}
+
+func (r *Reloader) register(conf any, apply func(conf any) error) {
+
+	key, ok := configKeyOf(conf)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.entries {
+		if entry.key == key {
+			entry.apply = append(entry.apply, apply)
+			return
+		}
+	}
+
+	entry := &reloadEntry{
+		key:   key,
+		apply: []func(conf any) error{apply},
+	}
+
+	// components are configured with the config read on construction,
+	// so only changes made since then are handed over
+	entry.currentKey = r.resolve(key)
+	if current, err := readChecked(entry.currentKey); err == nil {
+		entry.current = current
+	}
+
+	r.entries = append(r.entries, entry)
+}
+
+// resolve returns the key with the prefix of the struct in the registry unless it's prefixed already.
+func (r *Reloader) resolve(key configKey) configKey {
+	if key.prefix != "" || r.registry == nil {
+		return key
+	}
+	if prefix, ok := r.registry.prefixOf(key._type); ok {
+		key.prefix = prefix
+	}
+	return key
+}
+
+// Reload re-reads every registered config struct and hands the changed ones over.
+// Nothing is applied if any struct fails to be read or validated. It returns
+// the types of structs applied followed by their prefixes if any.
+func (r *Reloader) Reload() ([]string, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]configKey, len(r.entries))
+	confs := make([]any, len(r.entries))
+	var errs []error
+
+	for i, entry := range r.entries {
+		keys[i] = r.resolve(entry.key)
+		conf, err := readChecked(keys[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		confs[i] = conf
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	applied := make([]string, 0)
+
+	for i, entry := range r.entries {
+		if entry.current != nil && entry.currentKey == keys[i] && reflect.DeepEqual(entry.current, confs[i]) {
+			continue
+		}
+		failed := false
+		for _, apply := range entry.apply {
+			if err := apply(confs[i]); err != nil {
+				errs = append(errs, fmt.Errorf("failed to apply %s: %w", entry.key._type, err))
+				failed = true
+			}
+		}
+		if failed {
+			// keep the previous config, so the next reload hands the struct over again
+			continue
+		}
+		entry.current, entry.currentKey = confs[i], keys[i]
+		name := keys[i]._type.String()
+		if keys[i].prefix != "" {
+			name += " (" + keys[i].prefix + "*)"
+		}
+		applied = append(applied, name)
+	}
+
+	return applied, errors.Join(errs...)
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type reloadTestConfig struct {
+	Timeout int `env:"RELOAD_TEST_TIMEOUT" env-default:"10"`
+}
+
+func (conf reloadTestConfig) Validate() error {
+	if conf.Timeout < 0 {
+		return errors.New("timeout can't be negative")
+	}
+	return nil
+}
+
+func TestReloader(t *testing.T) {
+
+	reloader := NewReloader()
+
+	var applied []int
+	OnReload(reloader, func(conf reloadTestConfig) error {
+		applied = append(applied, conf.Timeout)
+		return nil
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		configs, err := reloader.Reload()
+		require.NoError(t, err)
+		require.Empty(t, configs)
+		require.Empty(t, applied)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		t.Setenv("RELOAD_TEST_TIMEOUT", "20")
+		configs, err := reloader.Reload()
+		require.NoError(t, err)
+		require.Equal(t, []string{"config.reloadTestConfig"}, configs)
+		require.Equal(t, []int{20}, applied)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv("RELOAD_TEST_TIMEOUT", "-1")
+		_, err := reloader.Reload()
+		require.ErrorContains(t, err, "timeout can't be negative")
+		require.Equal(t, []int{20}, applied)
+	})
+
+	t.Run("unparsable", func(t *testing.T) {
+		t.Setenv("RELOAD_TEST_TIMEOUT", "soon")
+		_, err := reloader.Reload()
+		require.Error(t, err)
+		require.Equal(t, []int{20}, applied)
+	})
+}
+
+type prefixedReloadable struct {
+	applied []int
+}
+
+func (p *prefixedReloadable) ReloadConfig() any {
+	return WithPrefix("QUEUE_", reloadTestConfig{})
+}
+
+func (p *prefixedReloadable) Reload(conf any) error {
+	p.applied = append(p.applied, conf.(reloadTestConfig).Timeout)
+	return nil
+}
+
+func TestReloaderPrefix(t *testing.T) {
+
+	t.Run("registry", func(t *testing.T) {
+		registry := NewRegistry(WithPrefix("CACHE_", reloadTestConfig{}))
+		reloader := NewReloader(WithRegistry(registry))
+
+		var applied []int
+		OnReload(reloader, func(conf reloadTestConfig) error {
+			applied = append(applied, conf.Timeout)
+			return nil
+		})
+
+		t.Setenv("RELOAD_TEST_TIMEOUT", "20")
+		configs, err := reloader.Reload()
+		require.NoError(t, err)
+		require.Empty(t, configs)
+
+		t.Setenv("CACHE_RELOAD_TEST_TIMEOUT", "30")
+		configs, err = reloader.Reload()
+		require.NoError(t, err)
+		require.Equal(t, []string{"config.reloadTestConfig (CACHE_*)"}, configs)
+		require.Equal(t, []int{30}, applied)
+	})
+
+	t.Run("reloadable", func(t *testing.T) {
+		reloader := NewReloader()
+		component := new(prefixedReloadable)
+		reloader.Add(component)
+
+		t.Setenv("QUEUE_RELOAD_TEST_TIMEOUT", "40")
+		_, err := reloader.Reload()
+		require.NoError(t, err)
+		require.Equal(t, []int{40}, component.applied)
+	})
+}
@@ -0,0 +1,484 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Format is a format config schema is written in, see WriteSchema.
+type Format string
+
+const (
+	// FormatEnv is the commented env file, see ConfigInfoEnv
+	FormatEnv Format = "env"
+	// FormatJSONSchema is JSON Schema of config files with variables as keys
+	FormatJSONSchema Format = "jsonschema"
+	// FormatMarkdown is a reference table per config section
+	FormatMarkdown Format = "markdown"
+	// FormatConfigMap is a Kubernetes ConfigMap with defaults and a Secret with secrets
+	FormatConfigMap Format = "configmap"
+	// FormatCompose is an environment block of a docker-compose service
+	FormatCompose Format = "compose"
+)
+
+// Formats lists formats supported by WriteSchema.
+var Formats = []Format{FormatEnv, FormatJSONSchema, FormatMarkdown, FormatConfigMap, FormatCompose}
+
+// ParseFormat returns the format by its name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown config format %q, one of %v expected", name, Formats)
+}
+
+// Filename returns the file schema of the format is generated to by default.
+func (format Format) Filename() string {
+	switch format {
+	case FormatJSONSchema:
+		return "config.schema.json"
+	case FormatMarkdown:
+		return "CONFIG.md"
+	case FormatConfigMap:
+		return "configmap.yaml"
+	case FormatCompose:
+		return "compose.environment.yaml"
+	default:
+		return "example.env"
+	}
+}
+
+// Variable describes a documented config variable, a field with a desc tag.
+type Variable struct {
+	Section  string
+	Env      string
+	Type     string
+	Desc     string
+	Default  string
+	Required bool
+	// Validate is the validate tag of the field
+	Validate string
+	Secret   bool
+
+	field Field
+}
+
+// HasDefault reports whether the variable has env-default tag.
+func (v Variable) HasDefault() bool {
+	_, ok := v.field.Default()
+	return ok
+}
+
+// Variables returns documented variables of the structs. A variable shared by several structs
+// is described by the first one.
+func Variables(structs ...any) []Variable {
+
+	variables := make([]Variable, 0)
+	seen := make(map[string]bool)
+
+	for _, key := range configKeys(structs) {
+
+		section := key.section()
+
+		for _, field := range key.fields() {
+
+			desc := field.Desc()
+			if desc == "" || desc == "-" || seen[field.Env] {
+				continue
+			}
+			seen[field.Env] = true
+
+			def, _ := field.Default()
+			validate := field.Tag.Get("validate")
+
+			variables = append(variables, Variable{
+				Section:  section,
+				Env:      field.Env,
+				Type:     field.Type.String(),
+				Desc:     desc,
+				Default:  def,
+				Required: field.Required() || hasRule(validate, "required"),
+				Validate: validate,
+				Secret:   field.Secret(),
+				field:    field,
+			})
+		}
+	}
+
+	return variables
+}
+
+// WriteSchema writes schema of the structs and the registered ones in the format.
+func WriteSchema(writer io.Writer, format Format, structs ...any) error {
+
+	if format == FormatEnv {
+		ConfigInfoEnv(writer, structs...)
+		return nil
+	}
+
+	variables := Variables(append(structs, Structs()...)...)
+
+	switch format {
+	case FormatJSONSchema:
+		return writeJSONSchema(writer, variables)
+	case FormatMarkdown:
+		writeMarkdown(writer, variables)
+		return nil
+	case FormatConfigMap:
+		return writeConfigMap(writer, variables)
+	case FormatCompose:
+		return writeCompose(writer, variables)
+	default:
+		_, err := ParseFormat(string(format))
+		return err
+	}
+}
+
+// rules splits the validate tag into rules and their params, e.g. gte=1.
+func rules(validate string) [][2]string {
+	parsed := make([][2]string, 0)
+	for _, rule := range strings.Split(validate, ",") {
+		if rule == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		parsed = append(parsed, [2]string{name, param})
+	}
+	return parsed
+}
+
+func hasRule(validate, name string) bool {
+	for _, rule := range rules(validate) {
+		if rule[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValue parses the raw value into a value of the field type for JSON,
+// durations and text values stay strings.
+func jsonValue(field Field, raw string) any {
+
+	if field.Type == durationType || isScalar(field.Type) || field.Type.Kind() == reflect.Map {
+		return raw
+	}
+
+	value := reflect.New(field.Type).Elem()
+	if err := setValue(value, raw, field.Separator()); err != nil {
+		return raw
+	}
+	return value.Interface()
+}
+
+var durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// jsonType returns JSON Schema type of values of the type.
+func jsonType(_type reflect.Type) map[string]any {
+
+	if _type == durationType {
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	}
+	if isScalar(_type) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch _type.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		bits := _type.Bits()
+		return map[string]any{"type": "integer", "minimum": -(1 << (bits - 1)), "maximum": 1<<(bits-1) - 1}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "minimum": 0, "maximum": uint64(1)<<_type.Bits() - 1}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Pointer:
+		return jsonType(_type.Elem())
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": jsonType(_type.Elem())}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+func jsonProperty(variable Variable) map[string]any {
+
+	field := variable.field
+	property := jsonType(field.Type)
+	property["description"] = variable.Desc
+
+	// defaults of secrets are placeholders, they aren't worth publishing
+	if variable.HasDefault() && !variable.Secret {
+		property["default"] = jsonValue(field, variable.Default)
+	}
+
+	number := property["type"] == "integer" || property["type"] == "number"
+	// limits of strings and lists apply to their lengths
+	lengthKeys := [2]string{"minLength", "maxLength"}
+	if property["type"] == "array" {
+		lengthKeys = [2]string{"minItems", "maxItems"}
+	}
+
+	limit := func(numberKey, lengthKey, param string) {
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		switch {
+		case number && numberKey != "":
+			property[numberKey] = n
+		case !number && lengthKey != "":
+			property[lengthKey] = int(n)
+		}
+	}
+
+	for _, rule := range rules(variable.Validate) {
+		name, param := rule[0], rule[1]
+		switch name {
+		case "gte", "min":
+			limit("minimum", lengthKeys[0], param)
+		case "lte", "max":
+			limit("maximum", lengthKeys[1], param)
+		case "gt":
+			limit("exclusiveMinimum", "", param)
+		case "lt":
+			limit("exclusiveMaximum", "", param)
+		case "len":
+			limit("const", lengthKeys[0], param)
+			limit("", lengthKeys[1], param)
+		case "oneof":
+			enum := make([]any, 0)
+			for _, option := range strings.Fields(param) {
+				enum = append(enum, jsonValue(field, option))
+			}
+			property["enum"] = enum
+		case "email":
+			property["format"] = "email"
+		case "url", "uri":
+			property["format"] = "uri"
+		case "hostname":
+			property["format"] = "hostname"
+		case "ip", "ipv4":
+			property["format"] = "ipv4"
+		case "ipv6":
+			property["format"] = "ipv6"
+		}
+	}
+
+	return property
+}
+
+// orderedObject keeps the order of keys in JSON, e.g. variables in the order of declaration.
+type orderedObject []struct {
+	key   string
+	value any
+}
+
+func (object *orderedObject) set(key string, value any) {
+	*object = append(*object, struct {
+		key   string
+		value any
+	}{key, value})
+}
+
+func (object orderedObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, item := range object {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(item.key)
+		value, err := json.Marshal(item.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeJSONSchema(writer io.Writer, variables []Variable) error {
+
+	properties := make(orderedObject, 0, len(variables))
+	required := make([]string, 0)
+
+	for _, variable := range variables {
+		properties.set(variable.Env, jsonProperty(variable))
+		if variable.Required && !variable.HasDefault() {
+			required = append(required, variable.Env)
+		}
+	}
+
+	schema := make(orderedObject, 0)
+	schema.set("$schema", "https://json-schema.org/draft/2020-12/schema")
+	schema.set("title", appName()+" config")
+	schema.set("type", "object")
+	schema.set("properties", properties)
+	if len(required) > 0 {
+		schema.set("required", required)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(schema)
+}
+
+func writeMarkdown(writer io.Writer, variables []Variable) {
+
+	escape := strings.NewReplacer("|", `\|`, "\n", " ").Replace
+	code := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + escape(s) + "`"
+	}
+
+	fmt.Fprintf(writer, "# %s config\n", appName())
+
+	section := ""
+	for _, variable := range variables {
+
+		if variable.Section != section {
+			section = variable.Section
+			fmt.Fprintf(writer, "\n## %s\n\n", section)
+			fmt.Fprintln(writer, "| Variable | Type | Default | Constraints | Description |")
+			fmt.Fprintln(writer, "| --- | --- | --- | --- | --- |")
+		}
+
+		constraints := make([]string, 0)
+		if variable.Required && !hasRule(variable.Validate, "required") {
+			constraints = append(constraints, "required")
+		}
+		if variable.Validate != "" {
+			constraints = append(constraints, variable.Validate)
+		}
+		if variable.Secret {
+			constraints = append(constraints, "secret")
+		}
+
+		def := code(variable.Default)
+		if variable.Secret && variable.Default != "" {
+			def = code(Redacted)
+		}
+
+		fmt.Fprintf(writer, "| %s | %s | %s | %s | %s |\n",
+			code(variable.Env),
+			code(variable.Type),
+			def,
+			code(strings.Join(constraints, ",")),
+			escape(variable.Desc),
+		)
+	}
+}
+
+type manifestMeta struct {
+	Name string `yaml:"name"`
+}
+
+type configMap struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Metadata   manifestMeta  `yaml:"metadata"`
+	Type       string        `yaml:"type,omitempty"`
+	Data       yaml.MapSlice `yaml:"data,omitempty"`
+	StringData yaml.MapSlice `yaml:"stringData,omitempty"`
+}
+
+// writeConfigMap writes a ConfigMap with defaults of variables and a Secret with empty secrets
+// to fill in, both named after the executable.
+func writeConfigMap(writer io.Writer, variables []Variable) error {
+
+	data := make(yaml.MapSlice, 0)
+	secrets := make(yaml.MapSlice, 0)
+
+	for _, variable := range variables {
+		if variable.Secret {
+			secrets = append(secrets, yaml.MapItem{Key: variable.Env, Value: ""})
+			continue
+		}
+		data = append(data, yaml.MapItem{Key: variable.Env, Value: variable.Default})
+	}
+
+	manifests := []configMap{
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Metadata:   manifestMeta{Name: appName() + "-config"},
+			Data:       data,
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   manifestMeta{Name: appName() + "-secret"},
+			Type:       "Opaque",
+			StringData: secrets,
+		},
+	}
+
+	for i, manifest := range manifests {
+		if i > 0 {
+			io.WriteString(writer, "---\n")
+		}
+		out, err := yaml.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		writer.Write(out)
+	}
+	return nil
+}
+
+// writeCompose writes an environment block with defaults of variables,
+// secrets are interpolated from the environment docker compose runs in.
+func writeCompose(writer io.Writer, variables []Variable) error {
+
+	environment := make(yaml.MapSlice, 0, len(variables))
+	for _, variable := range variables {
+		value := variable.Default
+		if variable.Secret {
+			value = "${" + variable.Env + "}"
+		}
+		environment = append(environment, yaml.MapItem{Key: variable.Env, Value: value})
+	}
+
+	out, err := yaml.Marshal(yaml.MapSlice{{Key: "environment", Value: environment}})
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(out)
+	return err
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// appName returns the executable name fit for Kubernetes object names.
+func appName() string {
+	name := strings.ToLower(filepath.Base(os.Args[0]))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		return "app"
+	}
+	return name
+}
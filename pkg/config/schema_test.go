@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type schemaTestConfig struct {
+	Port     uint16        `env:"SCHEMA_PORT" env-default:"8080" desc:"Server port" validate:"gte=1"`
+	Timeout  time.Duration `env:"SCHEMA_TIMEOUT" env-default:"15s" desc:"Request timeout"`
+	Mode     string        `env:"SCHEMA_MODE" env-default:"fast" desc:"Mode: fast | slow" validate:"oneof=fast slow"`
+	Tags     []string      `env:"SCHEMA_TAGS" desc:"Tags" validate:"max=3"`
+	Password string        `env:"SCHEMA_PASSWORD" env-default:"secret" desc:"Password"`
+	Name     string        `env:"SCHEMA_NAME" env-required:"true" desc:"Name"`
+	Hidden   string        `env:"SCHEMA_HIDDEN" desc:"-"`
+}
+
+func (schemaTestConfig) Desc() string {
+	return "Schema test config"
+}
+
+func TestWriteSchema(_t *testing.T) {
+
+	t := &T{_t}
+
+	t.Run("env", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteSchema(&buf, FormatEnv, TestConfig{}))
+		require.Equal(t, configInfoTestStraight, buf.String())
+	})
+
+	t.Run("jsonschema", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteSchema(&buf, FormatJSONSchema, schemaTestConfig{}))
+
+		var schema struct {
+			Type       string                    `json:"type"`
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &schema))
+
+		require.Equal(t, "object", schema.Type)
+		require.Equal(t, []string{"SCHEMA_NAME"}, schema.Required)
+		require.NotContains(t, schema.Properties, "SCHEMA_HIDDEN")
+
+		require.Equal(t, map[string]any{
+			"type":        "integer",
+			"description": "Server port",
+			"default":     float64(8080),
+			"minimum":     float64(1),
+			"maximum":     float64(65535),
+		}, schema.Properties["SCHEMA_PORT"])
+
+		require.Equal(t, []any{"fast", "slow"}, schema.Properties["SCHEMA_MODE"]["enum"])
+		require.Equal(t, "15s", schema.Properties["SCHEMA_TIMEOUT"]["default"])
+		require.Equal(t, "array", schema.Properties["SCHEMA_TAGS"]["type"])
+		require.Equal(t, float64(3), schema.Properties["SCHEMA_TAGS"]["maxItems"])
+		require.NotContains(t, schema.Properties["SCHEMA_PASSWORD"], "default")
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteSchema(&buf, FormatMarkdown, schemaTestConfig{}))
+		require.Equal(t, "# config config\n"+
+			"\n## Schema test config\n\n"+
+			"| Variable | Type | Default | Constraints | Description |\n"+
+			"| --- | --- | --- | --- | --- |\n"+
+			"| `SCHEMA_PORT` | `uint16` | `8080` | `gte=1` | Server port |\n"+
+			"| `SCHEMA_TIMEOUT` | `time.Duration` | `15s` |  | Request timeout |\n"+
+			"| `SCHEMA_MODE` | `string` | `fast` | `oneof=fast slow` | Mode: fast \\| slow |\n"+
+			"| `SCHEMA_TAGS` | `[]string` |  | `max=3` | Tags |\n"+
+			"| `SCHEMA_PASSWORD` | `string` | `******` | `secret` | Password |\n"+
+			"| `SCHEMA_NAME` | `string` |  | `required` | Name |\n",
+			buf.String())
+	})
+
+	t.Run("configmap", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteSchema(&buf, FormatConfigMap, TestConfig{}, schemaTestConfig{}))
+		require.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-config
+data:
+  VERBOSE: "true"
+  GREETING: Greeting
+  LEVEL: "123"
+  SCHEMA_PORT: "8080"
+  SCHEMA_TIMEOUT: 15s
+  SCHEMA_MODE: fast
+  SCHEMA_TAGS: ""
+  SCHEMA_NAME: ""
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: config-secret
+type: Opaque
+stringData:
+  SCHEMA_PASSWORD: ""
+`, buf.String())
+	})
+
+	t.Run("compose", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteSchema(&buf, FormatCompose, TestConfig{}, schemaTestConfig{}))
+		require.Equal(t, `environment:
+  VERBOSE: "true"
+  GREETING: Greeting
+  LEVEL: "123"
+  SCHEMA_PORT: "8080"
+  SCHEMA_TIMEOUT: 15s
+  SCHEMA_MODE: fast
+  SCHEMA_TAGS: ""
+  SCHEMA_PASSWORD: ${SCHEMA_PASSWORD}
+  SCHEMA_NAME: ""
+`, buf.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := ParseFormat("xml")
+		require.Error(t, err)
+		require.Error(t, WriteSchema(new(bytes.Buffer), Format("xml"), TestConfig{}))
+	})
+}
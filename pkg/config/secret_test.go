@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type secretTestConfig struct {
+	User     string `env:"VAULT_TEST_USER" env-default:"admin" desc:"User"`
+	Password string `env:"VAULT_TEST_PASS" env-default:"changeme" desc:"Password" secret:"true"`
+	// named like a secret, but it isn't one
+	KeyPrefix string `env:"VAULT_TEST_KEY_PREFIX" env-default:"app" desc:"Key prefix" secret:"false"`
+}
+
+func TestIsSecret(t *testing.T) {
+
+	for env, secret := range map[string]bool{
+		"DB_PASSWORD":      true,
+		"JWT_SECRET":       true,
+		"GITHUB_TOKEN":     true,
+		"STRIPE_API_KEY":   true,
+		"password":         true,
+		"CACHE_KEY_PREFIX": false,
+		"MAX_TOKENS":       false,
+		"KEYSPACE":         false,
+		"SECRET_LENGTH":    false,
+		"PASSWORDLESS":     false,
+	} {
+		require.Equal(t, secret, IsSecret(env), env)
+	}
+}
+
+func TestSecretFields(_t *testing.T) {
+
+	t := &T{_t}
+
+	t.Run("secret file", func(t *testing.T) {
+		file := writeFile(t, t.TempDir(), "password", "s3cr3t\n")
+		t.Setenv("VAULT_TEST_PASS_FILE", file)
+
+		var conf secretTestConfig
+		require.NoError(t, ReadConfigEnv(&conf))
+		require.Equal(t, "s3cr3t", conf.Password)
+	})
+
+	t.Run("value wins over secret file", func(t *testing.T) {
+		t.Setenv("VAULT_TEST_PASS_FILE", writeFile(t, t.TempDir(), "password", "s3cr3t"))
+		t.Setenv("VAULT_TEST_PASS", "plain")
+
+		var conf secretTestConfig
+		require.NoError(t, ReadConfigEnv(&conf))
+		require.Equal(t, "plain", conf.Password)
+	})
+
+	t.Run("missing secret file", func(t *testing.T) {
+		t.Setenv("VAULT_TEST_PASS_FILE", "/nonexistent/password")
+
+		var conf secretTestConfig
+		require.ErrorContains(t, ReadConfigEnv(&conf), "VAULT_TEST_PASS_FILE")
+
+		report, err := Preflight(secretTestConfig{})
+		require.NoError(t, err)
+		require.Len(t, report.Issues, 1)
+		require.Equal(t, "VAULT_TEST_PASS", report.Issues[0].Env)
+	})
+
+	t.Run("only secrets read from files", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		writeFile(t, ".", EnvFile, "VAULT_TEST_USER_FILE="+writeFile(t, t.TempDir(), "user", "root")+"\n")
+
+		var conf secretTestConfig
+		require.NoError(t, ReadConfigEnv(&conf))
+		require.Equal(t, "admin", conf.User)
+
+		report, err := Preflight(secretTestConfig{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"VAULT_TEST_USER_FILE"}, report.Unknown())
+	})
+
+	t.Run("effective values", func(t *testing.T) {
+		t.Setenv("VAULT_TEST_PASS", "plain")
+
+		var buf bytes.Buffer
+		require.NoError(t, EffectiveValuesEnv(&buf, secretTestConfig{}))
+		require.Equal(t, "\n#=== config.secretTestConfig ===#\n\n"+
+			"VAULT_TEST_USER=admin # default\nVAULT_TEST_PASS=****** # env\nVAULT_TEST_KEY_PREFIX=app # default\n", buf.String())
+	})
+
+	t.Run("config info", func(t *testing.T) {
+		var buf bytes.Buffer
+		ConfigInfoEnv(&buf, secretTestConfig{})
+		require.Equal(t, "\n#=== config.secretTestConfig ===#\n\n"+
+			"# User (string)\nVAULT_TEST_USER=admin\n"+
+			"# Password (string, secret, or a file in VAULT_TEST_PASS_FILE)\nVAULT_TEST_PASS=\n"+
+			"# Key prefix (string)\nVAULT_TEST_KEY_PREFIX=app\n", buf.String())
+	})
+
+	t.Run("invalid secret isn't quoted", func(t *testing.T) {
+		type config struct {
+			Pin int `env:"VAULT_TEST_PIN" secret:"true"`
+		}
+		t.Setenv("VAULT_TEST_PIN", "12a4")
+
+		var conf config
+		err := ReadConfigEnv(&conf)
+		require.ErrorContains(t, err, "VAULT_TEST_PIN: invalid value of int")
+		require.NotContains(t, err.Error(), "12a4")
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		cred := Credentials{User: "admin", Password: "plain"}
+		require.Equal(t, "admin:******", cred.String())
+		require.NotContains(t, cred.LogValue().String(), "plain")
+	})
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -44,3 +45,17 @@ func Test_RequestContext(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, requestID, actualGC.requestID)
 }
+
+func Test_RequestID(t *testing.T) {
+
+	require.Empty(t, RequestIDFromCtx(context.Background()))
+	require.Equal(t, "requestID", RequestIDFromCtx(WithRequestCtx(context.Background(), "requestID")))
+
+	require.Equal(t, "abc-123", RequestIDOrNew("abc-123"))
+
+	for _, incoming := range []string{"", "with space", "line\nbreak", strings.Repeat("a", 129)} {
+		generated := RequestIDOrNew(incoming)
+		require.NotEqual(t, incoming, generated)
+		require.Len(t, generated, 36)
+	}
+}
@@ -1,6 +1,13 @@
 package context
 
-import "context"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength limits request IDs taken from incoming requests
+const maxRequestIDLength = 128
 
 type requestContextKey struct{}
 
@@ -12,6 +19,11 @@ func (ctx *requestContext) Key() requestContextKey {
 	return requestContextKey{}
 }
 
+// RequestID returns the ID of the request the context belongs to.
+func (ctx *requestContext) RequestID() string {
+	return ctx.requestID
+}
+
 func WithRequestCtx(ctx context.Context, requestID string) context.Context {
 	return With(ctx, &requestContext{
 		requestID: requestID,
@@ -21,3 +33,29 @@ func WithRequestCtx(ctx context.Context, requestID string) context.Context {
 func RequestFromCtx(ctx context.Context) (*requestContext, bool) {
 	return From[*requestContext](ctx)
 }
+
+// RequestIDFromCtx returns the request ID stored by WithRequestCtx, empty if there's none.
+func RequestIDFromCtx(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	request, ok := RequestFromCtx(ctx)
+	if !ok {
+		return ""
+	}
+	return request.RequestID()
+}
+
+// RequestIDOrNew returns the incoming request ID or a new one if it's empty, too long
+// or has characters other than printable ASCII, so it's safe to log and echo.
+func RequestIDOrNew(incoming string) string {
+	if incoming == "" || len(incoming) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for _, char := range incoming {
+		if char < '!' || char > '~' {
+			return uuid.NewString()
+		}
+	}
+	return incoming
+}
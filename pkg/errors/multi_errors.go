@@ -67,7 +67,9 @@ func (er *MultiError) append(err error, critical bool, wrapper func(error) error
 			er.errs = multierror.Append(er.errs, wrapper(_err))
 		}
 	case *MultiError:
-		er.critical = err.critical
+		if err.critical != nil {
+			er.critical = err.critical
+		}
 		er.errs = multierror.Append(er.errs, wrapper(err.errs))
 	default:
 		er.errs = multierror.Append(er.errs, wrapper(err))
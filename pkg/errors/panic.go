@@ -0,0 +1,38 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a recovered panic along with the stack trace of the goroutine it happened in.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Recovered returns the error of the value recover returned, nil for nil.
+// Call it in the deferred function, so the stack trace leads to the panic.
+func Recovered(value any) *PanicError {
+	if value == nil {
+		return nil
+	}
+	return &PanicError{
+		Value: value,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value if the panic is called with an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// PanicHook reports recovered panics, e.g. to an error tracker.
+type PanicHook func(ctx context.Context, err *PanicError)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"io/fs"
 	"path"
+	"time"
 
 	"github.com/dgraph-io/dgo/v240"
+	"github.com/pkg/errors"
 	migrate "github.com/vishenosik/dmigrate"
 	"github.com/vishenosik/gocherry/pkg/config"
 	"google.golang.org/grpc"
@@ -24,6 +26,44 @@ type DgraphConfig struct {
 	GrpcServer  config.Server
 }
 
+// DgraphConfigEnv holds DgraphConfig variables, see NewClientEnv.
+type DgraphConfigEnv struct {
+	Host     string        `env:"DGRAPH_HOST" env-default:"localhost" desc:"Dgraph server host"`
+	Port     uint16        `env:"DGRAPH_GRPC_PORT" env-default:"9080" desc:"Dgraph gRPC port" validate:"gte=1"`
+	Timeout  time.Duration `env:"DGRAPH_TIMEOUT" env-default:"15s" desc:"Dgraph requests timeout"`
+	User     string        `env:"DGRAPH_USER" desc:"Dgraph ACL user"`
+	Password string        `env:"DGRAPH_PASSWORD" desc:"Dgraph ACL user's password" secret:"true"`
+}
+
+func (DgraphConfigEnv) Desc() string {
+	return "Dgraph connection settings"
+}
+
+// Config returns the client configuration set by the variables.
+func (conf DgraphConfigEnv) Config() DgraphConfig {
+	return DgraphConfig{
+		Credentials: config.Credentials{
+			User:     conf.User,
+			Password: conf.Password,
+		},
+		GrpcServer: config.Server{
+			Host:    conf.Host,
+			Port:    conf.Port,
+			Timeout: conf.Timeout,
+		},
+	}
+}
+
+// NewClientEnv creates a new Dgraph client configured by the environment, see DgraphConfigEnv.
+// The password may be read from a file set by DGRAPH_PASSWORD_FILE.
+func NewClientEnv(ctx context.Context) (*Client, error) {
+	var envConf DgraphConfigEnv
+	if err := config.ReadConfigEnv(&envConf); err != nil {
+		return nil, errors.Wrap(err, "dgraph: failed to read config")
+	}
+	return NewClientCtx(ctx, envConf.Config())
+}
+
 // NewClientCtx creates a new Dgraph client with the given context and configuration.
 // It establishes a connection to the Dgraph server using the provided credentials
 // and gRPC server details. The connection uses insecure transport credentials.
@@ -75,6 +115,16 @@ func (cli *Client) Migrate(migrations fs.FS) error {
 	return nil
 }
 
+// CheckHealth runs a read-only schema query against the Dgraph server.
+// Returns an error if the server can't be reached.
+func (cli *Client) CheckHealth(ctx context.Context) error {
+	txn := cli.Cli.NewReadOnlyTxn()
+	defer txn.Discard(ctx)
+
+	_, err := txn.Query(ctx, "schema {}")
+	return err
+}
+
 // Close terminates the connection to the Dgraph server.
 // The provided context is currently unused but maintained for future compatibility.
 // Always returns nil error.
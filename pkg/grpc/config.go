@@ -2,16 +2,10 @@ package grpc
 
 import (
 	"time"
-
-	"github.com/vishenosik/gocherry/pkg/config"
 )
 
-func init() {
-	config.AddStructs(ConfigEnv{})
-}
-
 type ConfigEnv struct {
-	Port    uint16        `env:"GRPC_PORT" env-default:"9090" desc:"grpc server port"`
+	Port    uint16        `env:"GRPC_PORT" env-default:"9090" desc:"grpc server port" validate:"gte=1"`
 	Timeout time.Duration `env:"GRPC_TIMEOUT" env-default:"15s" desc:"grpc timeout"`
 }
 
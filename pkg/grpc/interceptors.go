@@ -5,8 +5,10 @@ import (
 	"log/slog"
 	"time"
 
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
 	"github.com/vishenosik/gocherry/pkg/logs"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -16,13 +18,42 @@ func WithCustomInterceptor(interceptors ...grpc.ServerOption) ServerOption {
 	}
 }
 
+// WithLogInterceptors logs every request. Interceptors are chained, so they run after the ones passed before.
 func WithLogInterceptors() ServerOption {
 	return func(srv *Server) {
 		srv.interceptors = append(srv.interceptors,
 			// Unary
-			grpc.UnaryInterceptor(LogUnaryRequest(srv.log.With(logs.Operation("unary_interceptor")))),
+			grpc.ChainUnaryInterceptor(LogUnaryRequest(srv.log.With(logs.Operation("unary_interceptor")))),
 			// Stream
-			grpc.StreamInterceptor(LogStreamRequest(srv.log.With(logs.Operation("stream_interceptor")))),
+			grpc.ChainStreamInterceptor(LogStreamRequest(srv.log.With(logs.Operation("stream_interceptor")))),
+		)
+	}
+}
+
+// WithHeaderMetadata sends the metadata in the header of every response.
+func WithHeaderMetadata(md metadata.MD) ServerOption {
+	return func(srv *Server) {
+		srv.interceptors = append(srv.interceptors,
+			// Unary
+			grpc.ChainUnaryInterceptor(func(
+				ctx context.Context,
+				req interface{},
+				info *grpc.UnaryServerInfo,
+				handler grpc.UnaryHandler,
+			) (interface{}, error) {
+				_ = grpc.SetHeader(ctx, md)
+				return handler(ctx, req)
+			}),
+			// Stream
+			grpc.ChainStreamInterceptor(func(
+				srv interface{},
+				ss grpc.ServerStream,
+				info *grpc.StreamServerInfo,
+				handler grpc.StreamHandler,
+			) error {
+				_ = ss.SetHeader(md)
+				return handler(srv, ss)
+			}),
 		)
 	}
 }
@@ -38,6 +69,8 @@ func LogUnaryRequest(log *slog.Logger) grpc.UnaryServerInterceptor {
 		timeStart := time.Now()
 		resp, err := handler(ctx, req)
 
+		log := withRequestIDAttr(log, ctx)
+
 		if err != nil {
 			st, _ := status.FromError(err)
 			log.Error("request failed",
@@ -66,6 +99,8 @@ func LogStreamRequest(log *slog.Logger) grpc.StreamServerInterceptor {
 	) error {
 		timeStart := time.Now()
 
+		log := withRequestIDAttr(log, ss.Context())
+
 		log.Info("stream started",
 			slog.String("method", info.FullMethod),
 		)
@@ -90,3 +125,11 @@ func LogStreamRequest(log *slog.Logger) grpc.StreamServerInterceptor {
 		return err
 	}
 }
+
+// withRequestIDAttr adds the request ID of the context to log lines, see WithRequestID.
+func withRequestIDAttr(log *slog.Logger, ctx context.Context) *slog.Logger {
+	if requestID := _ctx.RequestIDFromCtx(ctx); requestID != "" {
+		return log.With(logs.RequestID(requestID))
+	}
+	return log
+}
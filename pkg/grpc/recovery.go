@@ -0,0 +1,78 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/vishenosik/gocherry/pkg/errors"
+	"github.com/vishenosik/gocherry/pkg/logs"
+)
+
+// WithRecovery recovers panics of services, logs them with stack traces and fails requests
+// with codes.Internal. Hooks report recovered panics, e.g. to an error tracker.
+// Pass it after WithLogInterceptors, so failed requests are logged too.
+func WithRecovery(hooks ...errors.PanicHook) ServerOption {
+	return func(srv *Server) {
+		log := srv.log.With(logs.Operation("recovery_interceptor"))
+		srv.interceptors = append(srv.interceptors,
+			// Unary
+			grpc.ChainUnaryInterceptor(RecoverUnary(log, hooks...)),
+			// Stream
+			grpc.ChainStreamInterceptor(RecoverStream(log, hooks...)),
+		)
+	}
+}
+
+func RecoverUnary(log *slog.Logger, hooks ...errors.PanicHook) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = recovered(ctx, log, info.FullMethod, errors.Recovered(value), hooks)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func RecoverStream(log *slog.Logger, hooks ...errors.PanicHook) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = recovered(ss.Context(), log, info.FullMethod, errors.Recovered(value), hooks)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs and reports the panic, the returned status hides the panic value from clients.
+func recovered(ctx context.Context, log *slog.Logger, method string, err *errors.PanicError, hooks []errors.PanicHook) error {
+
+	withRequestIDAttr(log, ctx).Error("request panicked",
+		slog.String("method", method),
+		logs.Error(err),
+		logs.Stack(err.Stack),
+	)
+
+	for _, hook := range hooks {
+		if hook != nil {
+			hook(ctx, err)
+		}
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
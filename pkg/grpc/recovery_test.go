@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
+	"github.com/vishenosik/gocherry/pkg/errors"
+)
+
+func TestRecovery(t *testing.T) {
+
+	var buf bytes.Buffer
+	var reported *errors.PanicError
+
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	hook := func(ctx context.Context, err *errors.PanicError) {
+		reported = err
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		interceptor := RecoverUnary(log, hook)
+		ctx := _ctx.WithRequestCtx(context.Background(), "abc-123")
+
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+			func(ctx context.Context, req any) (any, error) {
+				panic("boom")
+			},
+		)
+		require.Equal(t, codes.Internal, status.Code(err))
+		require.NotContains(t, err.Error(), "boom")
+
+		require.Equal(t, "boom", reported.Value)
+		require.Contains(t, buf.String(), `err="panic: boom"`)
+		require.Contains(t, buf.String(), "request_id=abc-123")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		interceptor := RecoverStream(log)
+
+		err := interceptor(nil, &requestIDStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"},
+			func(srv any, stream grpc.ServerStream) error {
+				panic("boom")
+			},
+		)
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
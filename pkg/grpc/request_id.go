@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
+)
+
+const (
+	// RequestIDMetadata is the metadata key of the request ID, the X-Request-ID header of grpc
+	RequestIDMetadata = "x-request-id"
+)
+
+// WithRequestID takes request IDs from the incoming metadata or generates them, stores them
+// in request contexts and sends them back in response headers. Pass it before WithLogInterceptors,
+// so log lines of requests carry their IDs.
+func WithRequestID() ServerOption {
+	return func(srv *Server) {
+		srv.interceptors = append(srv.interceptors,
+			grpc.ChainUnaryInterceptor(RequestIDUnary()),
+			grpc.ChainStreamInterceptor(RequestIDStream()),
+		)
+	}
+}
+
+// RequestIDUnary stores the request ID in the context of unary requests, see WithRequestID.
+func RequestIDUnary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx = withRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, _ctx.RequestIDFromCtx(ctx)))
+		return handler(ctx, req)
+	}
+}
+
+// RequestIDStream stores the request ID in the context of streams, see WithRequestID.
+func RequestIDStream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := withRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(RequestIDMetadata, _ctx.RequestIDFromCtx(ctx)))
+		return handler(srv, &requestIDStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// requestIDStream is a stream with the request ID in its context.
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *requestIDStream) Context() context.Context {
+	return ss.ctx
+}
+
+// withRequestID stores the incoming request ID or a new one in the context.
+func withRequestID(ctx context.Context) context.Context {
+	incoming := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadata); len(values) > 0 {
+			incoming = values[0]
+		}
+	}
+	return _ctx.WithRequestCtx(ctx, _ctx.RequestIDOrNew(incoming))
+}
+
+// PropagateRequestID returns dial options sending the request ID of the call context
+// in the metadata of outgoing requests, so calls to other services are traced by the same ID.
+//
+//	conn, err := grpc.NewClient(target, append(opts, _grpc.PropagateRequestID()...)...)
+func PropagateRequestID() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(
+			ctx context.Context,
+			method string,
+			req, reply interface{},
+			cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker,
+			opts ...grpc.CallOption,
+		) error {
+			return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(
+			ctx context.Context,
+			desc *grpc.StreamDesc,
+			cc *grpc.ClientConn,
+			method string,
+			streamer grpc.Streamer,
+			opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+		}),
+	}
+}
+
+// outgoingRequestID adds the request ID of the context to the outgoing metadata unless it's set.
+func outgoingRequestID(ctx context.Context) context.Context {
+	requestID := _ctx.RequestIDFromCtx(ctx)
+	if requestID == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDMetadata)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDMetadata, requestID)
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
+)
+
+func TestRequestID(t *testing.T) {
+
+	interceptor := RequestIDUnary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	requestID := func(ctx context.Context) string {
+		var requestID string
+		_, err := interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			requestID = _ctx.RequestIDFromCtx(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return requestID
+	}
+
+	t.Run("incoming", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadata, "abc-123"))
+		require.Equal(t, "abc-123", requestID(ctx))
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		require.NotEmpty(t, requestID(context.Background()))
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		ctx := outgoingRequestID(_ctx.WithRequestCtx(context.Background(), "abc-123"))
+		md, ok := metadata.FromOutgoingContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, []string{"abc-123"}, md.Get(RequestIDMetadata))
+
+		// the ID set by the caller wins
+		ctx = metadata.AppendToOutgoingContext(context.Background(), RequestIDMetadata, "caller")
+		md, _ = metadata.FromOutgoingContext(outgoingRequestID(_ctx.WithRequestCtx(ctx, "abc-123")))
+		require.Equal(t, []string{"caller"}, md.Get(RequestIDMetadata))
+	})
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"log/slog"
 	"net"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/vishenosik/gocherry/pkg/config"
 	"github.com/vishenosik/gocherry/pkg/logs"
@@ -25,6 +29,16 @@ type Server struct {
 	interceptors []grpc.ServerOption
 	// config
 	config Config
+	// started is closed once the server listens to its address
+	started     chan struct{}
+	startedOnce sync.Once
+	serving     atomic.Bool
+	// listener is used instead of listening to the config address when set
+	listener net.Listener
+	// listenFunc listens to the config address, net.Listen by default
+	listenFunc func(network, address string) (net.Listener, error)
+	// health implements grpc.health.v1 service
+	health *grpchealth.Server
 }
 
 type Config struct {
@@ -59,8 +73,10 @@ func NewGrpcServer(
 	}
 
 	srv := &Server{
-		log:    log,
-		config: config,
+		log:     log,
+		config:  config,
+		started: make(chan struct{}),
+		health:  grpchealth.NewServer(),
 	}
 
 	for _, opt := range opts {
@@ -69,6 +85,9 @@ func NewGrpcServer(
 
 	srv.server = grpc.NewServer(srv.interceptors...)
 
+	srv.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv.server, srv.health)
+
 	for _, service := range services {
 		service.RegisterService(srv.server)
 	}
@@ -90,13 +109,22 @@ func (a *Server) Start(_ context.Context) error {
 
 	log.Info("starting server")
 
-	listener, err := net.Listen("tcp", a.config.Server.String())
+	listener, err := a.listen()
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
 
 	log.Info("server is running", slog.String("addr", listener.Addr().String()))
 
+	a.startedOnce.Do(func() { close(a.started) })
+
+	a.serving.Store(true)
+	a.SetServingStatus(true)
+	defer func() {
+		a.serving.Store(false)
+		a.SetServingStatus(false)
+	}()
+
 	if err := a.server.Serve(listener); err != nil {
 		return errors.Wrap(err, op)
 	}
@@ -104,6 +132,67 @@ func (a *Server) Start(_ context.Context) error {
 	return nil
 }
 
+// WithServerLog replaces the logger the server logs with, logs.SetupLogger by default.
+// Interceptors log with the logger set when they are added, so it's expected to be passed first.
+func WithServerLog(log *slog.Logger) ServerOption {
+	return func(srv *Server) {
+		if log != nil {
+			srv.log = log.With(appComponent())
+		}
+	}
+}
+
+// WithListener makes the server accept connections on the listener instead of the config address.
+func WithListener(listener net.Listener) ServerOption {
+	return func(srv *Server) {
+		if listener != nil {
+			srv.listener = listener
+		}
+	}
+}
+
+// WithListenFunc makes the server listen to the config address with the function,
+// e.g. to take over a listener inherited from the previous process.
+func WithListenFunc(listen func(network, address string) (net.Listener, error)) ServerOption {
+	return func(srv *Server) {
+		if listen != nil {
+			srv.listenFunc = listen
+		}
+	}
+}
+
+func (a *Server) listen() (net.Listener, error) {
+	if a.listener != nil {
+		return a.listener, nil
+	}
+	if a.listenFunc != nil {
+		return a.listenFunc("tcp", a.config.Server.String())
+	}
+	return net.Listen("tcp", a.config.Server.String())
+}
+
+// Started returns a channel which is closed once the server listens to its address.
+func (a *Server) Started() <-chan struct{} {
+	return a.started
+}
+
+// SetServingStatus sets the status reported by grpc.health.v1 service for the whole server.
+func (a *Server) SetServingStatus(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	a.health.SetServingStatus("", status)
+}
+
+// CheckHealth reports an error unless the server accepts connections.
+func (a *Server) CheckHealth(_ context.Context) error {
+	if !a.serving.Load() {
+		return errors.New("grpc server is not serving")
+	}
+	return nil
+}
+
 func (a *Server) Stop(ctx context.Context) error {
 
 	const op = "grpc.Server.Stop"
@@ -111,7 +200,20 @@ func (a *Server) Stop(ctx context.Context) error {
 	a.log.With(logs.Operation(op)).
 		Info("stopping server", slog.Any("port", a.config.Server.Port))
 
-	a.server.GracefulStop()
+	a.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		a.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		a.log.Warn("graceful stop timeout exceeded, closing connections", logs.Error(ctx.Err()))
+		a.server.Stop()
+	}
 	return nil
 }
 
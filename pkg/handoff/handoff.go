@@ -0,0 +1,325 @@
+// Package handoff passes listening sockets between processes, so a new binary takes over
+// the ports while the old one drains.
+//
+// Listeners are inherited either from systemd socket activation (LISTEN_FDS, LISTEN_PID and
+// LISTEN_FDNAMES variables) or from the previous process started the new one by Handoff.Upgrade.
+package handoff
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// systemd socket activation variables, see sd_listen_fds(3)
+	envListenFds     = "LISTEN_FDS"
+	envListenPid     = "LISTEN_PID"
+	envListenFdNames = "LISTEN_FDNAMES"
+
+	// envReadyFd is the pipe the upgraded process reports readiness to the previous one with
+	envReadyFd = "GOCHERRY_READY_FD"
+
+	// listenFdsStart is the first inherited file descriptor
+	listenFdsStart = 3
+)
+
+var (
+	ErrUpgradeInProgress = errors.New("upgrade is in progress already")
+	ErrUpgradeFailed     = errors.New("upgraded process exited before it got ready")
+)
+
+type inherited struct {
+	name     string
+	listener net.Listener
+	used     bool
+}
+
+// Handoff hands listeners out to servers and passes them over to the upgraded process.
+type Handoff struct {
+	mu        sync.Mutex
+	inherited []*inherited
+	// active are listeners handed out, they are passed over on upgrade
+	active    []net.Listener
+	readyFile *os.File
+	upgrading bool
+}
+
+// New takes over listeners inherited from systemd or the previous process.
+// Handoff variables are unset, so they aren't inherited by child processes.
+func New() (*Handoff, error) {
+
+	h := new(Handoff)
+
+	if fd, ok := os.LookupEnv(envReadyFd); ok {
+		readyFd, err := strconv.Atoi(fd)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", envReadyFd)
+		}
+		syscall.CloseOnExec(readyFd)
+		h.readyFile = os.NewFile(uintptr(readyFd), "ready")
+	}
+
+	listeners, err := inheritListeners(h.readyFile != nil)
+	if err != nil {
+		return nil, err
+	}
+	h.inherited = listeners
+
+	for _, env := range []string{envListenFds, envListenPid, envListenFdNames, envReadyFd} {
+		os.Unsetenv(env)
+	}
+
+	return h, nil
+}
+
+func inheritListeners(upgraded bool) ([]*inherited, error) {
+
+	fds, ok := os.LookupEnv(envListenFds)
+	if !ok {
+		return nil, nil
+	}
+
+	// the previous process can't know the pid of the upgraded one, so LISTEN_PID is checked
+	// for socket activation only
+	if !upgraded && os.Getenv(envListenPid) != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(fds)
+	if err != nil || count < 0 {
+		return nil, errors.Errorf("invalid %s: %s", envListenFds, fds)
+	}
+
+	names := strings.Split(os.Getenv(envListenFdNames), ":")
+
+	listeners := make([]*inherited, 0, count)
+
+	for i := range count {
+		fd := listenFdsStart + i
+		syscall.CloseOnExec(fd)
+
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to inherit listener %s from fd %d", name, fd)
+		}
+
+		listeners = append(listeners, &inherited{name: name, listener: listener})
+	}
+
+	return listeners, nil
+}
+
+// Listen returns the inherited listener bound to the address or listens to it.
+func (h *Handoff) Listen(network, address string) (net.Listener, error) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, inh := range h.inherited {
+		if inh.used || !sameAddr(network, address, inh.listener.Addr()) {
+			continue
+		}
+		inh.used = true
+		h.active = append(h.active, inh.listener)
+		return inh.listener, nil
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	h.active = append(h.active, listener)
+	return listener, nil
+}
+
+// Inherited returns names of the inherited listeners.
+func (h *Handoff) Inherited() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	names := make([]string, 0, len(h.inherited))
+	for _, inh := range h.inherited {
+		names = append(names, inh.name)
+	}
+	return names
+}
+
+// CloseUnused closes inherited listeners no server has taken over by Listen, e.g. of a port
+// removed from the config, so the ports aren't held. It returns names of the closed listeners.
+// It's called once servers are started, listeners inherited later are listened to anew.
+func (h *Handoff) CloseUnused() ([]string, error) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var errs []error
+	closed := make([]string, 0)
+	used := make([]*inherited, 0, len(h.inherited))
+
+	for _, inh := range h.inherited {
+		if inh.used {
+			used = append(used, inh)
+			continue
+		}
+		if err := inh.listener.Close(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to close listener %s", inh.name))
+		}
+		closed = append(closed, inh.name)
+	}
+
+	h.inherited = used
+	return closed, stderrors.Join(errs...)
+}
+
+// Ready reports the previous process the upgrade is done, so it starts draining.
+// It does nothing unless the process is started by Handoff.Upgrade.
+func (h *Handoff) Ready() error {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.readyFile == nil {
+		return nil
+	}
+
+	_, err := h.readyFile.Write([]byte{1})
+	h.readyFile.Close()
+	h.readyFile = nil
+	return errors.Wrap(err, "failed to report readiness")
+}
+
+// Upgrade starts the current executable with the same arguments passing the active listeners
+// over and waits until it reports readiness by Handoff.Ready.
+// The listeners stay open, so the caller keeps serving until it stops.
+func (h *Handoff) Upgrade(ctx context.Context) (pid int, err error) {
+
+	h.mu.Lock()
+	if h.upgrading {
+		h.mu.Unlock()
+		return 0, ErrUpgradeInProgress
+	}
+	h.upgrading = true
+	active := append([]net.Listener(nil), h.active...)
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.upgrading = false
+		h.mu.Unlock()
+	}()
+
+	files := make([]*os.File, 0, len(active)+1)
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	for _, listener := range active {
+		filer, ok := listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return 0, errors.Errorf("listener %s can't be passed over", listener.Addr())
+		}
+		file, err := filer.File()
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to pass listener %s over", listener.Addr())
+		}
+		files = append(files, file)
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create readiness pipe")
+	}
+	defer readyReader.Close()
+	files = append(files, readyWriter)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to find executable")
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%d", envListenFds, len(active)),
+		fmt.Sprintf("%s=%d", envReadyFd, listenFdsStart+len(active)),
+	)
+
+	if err := cmd.Start(); err != nil {
+		return 0, errors.Wrap(err, "failed to start upgraded process")
+	}
+
+	// the child holds its own copy of the pipe, so reading gets EOF once it exits
+	readyWriter.Close()
+	files = files[:len(files)-1]
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := readyReader.Read(buf); err != nil {
+			ready <- ErrUpgradeFailed
+			return
+		}
+		ready <- nil
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			_ = cmd.Wait()
+			return 0, err
+		}
+		// the upgraded process outlives this one, it's reparented once this one exits
+		go func() { _ = cmd.Wait() }()
+		return cmd.Process.Pid, nil
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return 0, errors.Wrap(ctx.Err(), "upgraded process didn't get ready")
+	}
+}
+
+// sameAddr reports whether the listener address is the one to listen to,
+// e.g. [::]:8080 is the address :8080.
+func sameAddr(network, address string, addr net.Addr) bool {
+
+	if !strings.HasPrefix(network, "tcp") {
+		return addr.Network() == network && addr.String() == address
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	want, err := net.ResolveTCPAddr(network, address)
+	if err != nil || want.Port != tcpAddr.Port {
+		return false
+	}
+
+	if want.IP == nil || want.IP.IsUnspecified() {
+		return tcpAddr.IP == nil || tcpAddr.IP.IsUnspecified()
+	}
+	return want.IP.Equal(tcpAddr.IP)
+}
@@ -0,0 +1,126 @@
+package handoff
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const envTestChild = "HANDOFF_TEST_CHILD"
+
+func TestSameAddr(t *testing.T) {
+
+	tcp := func(address string) net.Addr {
+		addr, err := net.ResolveTCPAddr("tcp", address)
+		require.NoError(t, err)
+		return addr
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		addr    net.Addr
+		same    bool
+	}{
+		{"any host", ":8080", tcp("[::]:8080"), true},
+		{"ipv4 any host", "0.0.0.0:8080", tcp("[::]:8080"), true},
+		{"loopback", "127.0.0.1:8080", tcp("127.0.0.1:8080"), true},
+		{"other port", ":8080", tcp("[::]:8081"), false},
+		{"other host", "127.0.0.1:8080", tcp("[::]:8080"), false},
+		{"unix socket", "/tmp/app.sock", &net.UnixAddr{Net: "unix", Name: "/tmp/app.sock"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.same, sameAddr("tcp", tt.address, tt.addr))
+		})
+	}
+}
+
+func TestCloseUnused(t *testing.T) {
+
+	listen := func(name string) *inherited {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { listener.Close() })
+		return &inherited{name: name, listener: listener}
+	}
+
+	http, grpc := listen("http"), listen("grpc")
+	h := &Handoff{inherited: []*inherited{http, grpc}}
+
+	listener, err := h.Listen("tcp", http.listener.Addr().String())
+	require.NoError(t, err)
+	require.Equal(t, http.listener, listener)
+
+	closed, err := h.CloseUnused()
+	require.NoError(t, err)
+	require.Equal(t, []string{"grpc"}, closed)
+	require.Equal(t, []string{"http"}, h.Inherited())
+
+	_, err = net.Dial("tcp", grpc.listener.Addr().String())
+	require.Error(t, err)
+
+	closed, err = h.CloseUnused()
+	require.NoError(t, err)
+	require.Empty(t, closed)
+}
+
+func TestUpgrade(t *testing.T) {
+
+	if address, ok := os.LookupEnv(envTestChild); ok {
+		upgradedProcess(t, address)
+		return
+	}
+
+	h, err := New()
+	require.NoError(t, err)
+	require.Empty(t, h.Inherited())
+
+	listener, err := h.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	t.Setenv(envTestChild, listener.Addr().String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pid, err := h.Upgrade(ctx)
+	require.NoError(t, err)
+	require.NotZero(t, pid)
+
+	// only the upgraded process accepts connections on the shared socket
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	greeting, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "upgraded", string(greeting))
+}
+
+// upgradedProcess serves one connection on the inherited listener.
+func upgradedProcess(t *testing.T, address string) {
+
+	h, err := New()
+	require.NoError(t, err)
+	require.Len(t, h.Inherited(), 1)
+
+	listener, err := h.Listen("tcp", address)
+	require.NoError(t, err)
+	require.NoError(t, h.Ready())
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("upgraded"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,242 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pkg/errors"
+)
+
+const (
+	LivenessRoute  = "/healthz"
+	ReadinessRoute = "/readyz"
+
+	defaultCheckTimeout = 5 * time.Second
+)
+
+var (
+	// app is not ready to serve requests
+	ErrNotReady = errors.New("app is not ready")
+)
+
+// Checker is implemented by components able to report their health.
+// CheckHealth returns nil when the component is healthy.
+type Checker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) CheckHealth(ctx context.Context) error { return f(ctx) }
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// ComponentReport is the result of a single component check.
+type ComponentReport struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report aggregates results of component checks.
+type Report struct {
+	Status     Status            `json:"status"`
+	Components []ComponentReport `json:"components,omitempty"`
+}
+
+func (rep Report) Up() bool {
+	return rep.Status == StatusUp
+}
+
+type entry struct {
+	name    string
+	checker Checker
+}
+
+// Registry aggregates health checkers of the app components.
+//
+// Liveness checkers report if the component itself is alive,
+// readiness checkers additionally report if it can serve requests, e.g. its storage is reachable.
+type Registry struct {
+	mu        sync.RWMutex
+	liveness  []entry
+	readiness []entry
+
+	ready   atomic.Bool
+	timeout time.Duration
+}
+
+type RegistryOption func(*Registry)
+
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
+		timeout: defaultCheckTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithCheckTimeout limits the time each checker has to report.
+func WithCheckTimeout(timeout time.Duration) RegistryOption {
+	return func(r *Registry) {
+		if timeout > 0 {
+			r.timeout = timeout
+		}
+	}
+}
+
+// AddLiveness registers the checker for both liveness and readiness probes.
+func (r *Registry) AddLiveness(name string, checker Checker) {
+	if checker == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness = append(r.liveness, entry{name: name, checker: checker})
+	r.readiness = append(r.readiness, entry{name: name, checker: checker})
+}
+
+// AddReadiness registers the checker for readiness probe only.
+func (r *Registry) AddReadiness(name string, checker Checker) {
+	if checker == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness = append(r.readiness, entry{name: name, checker: checker})
+}
+
+// SetReady marks the app ready or not ready to serve requests.
+func (r *Registry) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+func (r *Registry) IsReady() bool {
+	return r.ready.Load()
+}
+
+// Live runs liveness checkers.
+func (r *Registry) Live(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := append([]entry(nil), r.liveness...)
+	r.mu.RUnlock()
+
+	return r.check(ctx, entries)
+}
+
+// Ready runs readiness checkers. The report is down while the app is not marked ready.
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := append([]entry(nil), r.readiness...)
+	r.mu.RUnlock()
+
+	report := r.check(ctx, entries)
+
+	if !r.IsReady() {
+		report.Status = StatusDown
+		report.Components = append([]ComponentReport{{
+			Name:    "app",
+			Status:  StatusDown,
+			Latency: time.Duration(0).String(),
+			Error:   ErrNotReady.Error(),
+		}}, report.Components...)
+	}
+
+	return report
+}
+
+func (r *Registry) check(ctx context.Context, entries []entry) Report {
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make([]ComponentReport, len(entries)),
+	}
+
+	var wg sync.WaitGroup
+
+	for i, entry := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = r.checkEntry(ctx, entry)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if component.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+func (r *Registry) checkEntry(ctx context.Context, entry entry) ComponentReport {
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	timeStart := time.Now()
+	err := entry.checker.CheckHealth(ctx)
+
+	component := ComponentReport{
+		Name:    entry.name,
+		Status:  StatusUp,
+		Latency: time.Since(timeStart).String(),
+	}
+
+	if err != nil {
+		component.Status = StatusDown
+		component.Error = err.Error()
+	}
+
+	return component
+}
+
+// LivenessHandler serves the liveness report as JSON.
+func (r *Registry) LivenessHandler() http.Handler {
+	return reportHandler(r.Live)
+}
+
+// ReadinessHandler serves the readiness report as JSON.
+func (r *Registry) ReadinessHandler() http.Handler {
+	return reportHandler(r.Ready)
+}
+
+// Routers mounts liveness and readiness handlers to the router.
+func (r *Registry) Routers(router chi.Router) {
+	router.Method(http.MethodGet, LivenessRoute, r.LivenessHandler())
+	router.Method(http.MethodGet, ReadinessRoute, r.ReadinessHandler())
+}
+
+func reportHandler(check func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		report := check(req.Context())
+
+		statusCode := http.StatusOK
+		if !report.Up() {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
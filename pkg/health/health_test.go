@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+
+	up := CheckerFunc(func(context.Context) error { return nil })
+	down := CheckerFunc(func(context.Context) error { return errors.New("connection refused") })
+
+	t.Run("liveness", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.AddLiveness("server", up)
+		registry.AddReadiness("cache", down)
+
+		report := registry.Live(context.Background())
+		require.True(t, report.Up())
+		require.Len(t, report.Components, 1)
+		require.Equal(t, "server", report.Components[0].Name)
+	})
+
+	t.Run("readiness", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.AddLiveness("server", up)
+		registry.AddReadiness("cache", down)
+		registry.SetReady(true)
+
+		report := registry.Ready(context.Background())
+		require.False(t, report.Up())
+		require.Len(t, report.Components, 2)
+		require.Equal(t, StatusUp, report.Components[0].Status)
+		require.Equal(t, StatusDown, report.Components[1].Status)
+		require.Equal(t, "connection refused", report.Components[1].Error)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		registry := NewRegistry()
+		registry.AddLiveness("server", up)
+
+		report := registry.Ready(context.Background())
+		require.False(t, report.Up())
+		require.Equal(t, ErrNotReady.Error(), report.Components[0].Error)
+
+		registry.SetReady(true)
+		require.True(t, registry.Ready(context.Background()).Up())
+	})
+}
+
+func TestHandlers(t *testing.T) {
+
+	registry := NewRegistry()
+	registry.AddLiveness("server", CheckerFunc(func(context.Context) error { return nil }))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		code    int
+	}{
+		{"liveness", registry.LivenessHandler(), http.StatusOK},
+		{"readiness", registry.ReadinessHandler(), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			require.Equal(t, tt.code, w.Code)
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var report Report
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			require.NotEmpty(t, report.Components)
+		})
+	}
+}
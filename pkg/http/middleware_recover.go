@@ -0,0 +1,86 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
+	"github.com/vishenosik/gocherry/pkg/errors"
+	"github.com/vishenosik/gocherry/pkg/logs"
+)
+
+type recoverer struct {
+	log   *slog.Logger
+	hooks []errors.PanicHook
+}
+
+type RecovererOption func(*recoverer)
+
+// WithRecoverLog replaces the logger recovered panics are logged with.
+func WithRecoverLog(log *slog.Logger) RecovererOption {
+	return func(rc *recoverer) {
+		if log != nil {
+			rc.log = log
+		}
+	}
+}
+
+// WithPanicHook reports recovered panics with the hook, e.g. to an error tracker.
+func WithPanicHook(hook errors.PanicHook) RecovererOption {
+	return func(rc *recoverer) {
+		if hook != nil {
+			rc.hooks = append(rc.hooks, hook)
+		}
+	}
+}
+
+// Recoverer recovers panics of handlers, logs them with stack traces
+// and responds with the 500 ErrorResponse. http.ErrAbortHandler is panicked again,
+// so the server aborts the response as it expects.
+func Recoverer(opts ...RecovererOption) Middleware {
+
+	rc := &recoverer{
+		log: logs.SetupLogger().With(appComponent()),
+	}
+
+	for _, opt := range opts {
+		opt(rc)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				if value == http.ErrAbortHandler {
+					panic(value)
+				}
+
+				err := errors.Recovered(value)
+
+				log := rc.log
+				if requestID := _ctx.RequestIDFromCtx(r.Context()); requestID != "" {
+					log = log.With(logs.RequestID(requestID))
+				}
+				log.Error("request panicked",
+					slog.String("method", r.Method+" "+r.URL.Path),
+					logs.Error(err),
+					logs.Stack(err.Stack),
+				)
+
+				for _, hook := range rc.hooks {
+					hook(r.Context(), err)
+				}
+
+				// the panic value may hold details not meant for clients
+				SendErrors(w, http.StatusInternalServerError,
+					NewError(http.StatusInternalServerError, errors.New("internal error")),
+				)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+)
+
+// RequestID takes the request ID from the X-Request-ID header or generates one,
+// stores it in the request context and echoes it in the response header.
+// Put it before RequestLogger, so log lines of the request carry the ID.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := _ctx.RequestIDOrNew(r.Header.Get(RequestIDHeader))
+			w.Header().Set(RequestIDHeader, requestID)
+			next.ServeHTTP(w, r.WithContext(_ctx.WithRequestCtx(r.Context(), requestID)))
+		})
+	}
+}
+
+// PropagateRequestID sends the request ID of the request context in the X-Request-ID header
+// of outgoing requests, so calls to other services are traced by the same ID.
+// The default transport is used if next is nil.
+//
+//	client := &http.Client{Transport: _http.PropagateRequestID(nil)}
+func PropagateRequestID(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requestID := _ctx.RequestIDFromCtx(r.Context())
+		if requestID == "" || r.Header.Get(RequestIDHeader) != "" {
+			return next.RoundTrip(r)
+		}
+		// round trippers must not modify the request
+		r = r.Clone(r.Context())
+		r.Header.Set(RequestIDHeader, requestID)
+		return next.RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/vishenosik/gocherry/pkg/api"
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
 	"github.com/vishenosik/gocherry/pkg/logs"
 )
 
@@ -25,6 +26,15 @@ func newRequestLogger() *requestLogger {
 	}
 }
 
+// WithRequestLog replaces the logger requests are logged with.
+func WithRequestLog(log *slog.Logger) RequestLoggerOption {
+	return func(rl *requestLogger) {
+		if log != nil {
+			rl.log = log
+		}
+	}
+}
+
 func (rl *requestLogger) WriteHeader(statusCode int) {
 	rl.statusCode = statusCode
 	rl.ResponseWriter.WriteHeader(statusCode)
@@ -58,6 +68,15 @@ func RequestLogger(opts ...RequestLoggerOption) func(next http.Handler) http.Han
 				logs.Took(timeStart),
 			)
 
+			// the ID is set by RequestID, the response header is looked at if the middleware follows the logger
+			requestID := _ctx.RequestIDFromCtx(r.Context())
+			if requestID == "" {
+				requestID = rl.Header().Get(RequestIDHeader)
+			}
+			if requestID != "" {
+				log = log.With(logs.RequestID(requestID))
+			}
+
 			switch {
 			case api.IsClientError(rl.statusCode) || api.IsServerError(rl.statusCode):
 				log.Error("request failed with error")
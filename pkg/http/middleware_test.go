@@ -1 +1,114 @@
 package http
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	_ctx "github.com/vishenosik/gocherry/pkg/context"
+	"github.com/vishenosik/gocherry/pkg/errors"
+)
+
+func TestRequestID(t *testing.T) {
+
+	var requestID string
+	handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = _ctx.RequestIDFromCtx(r.Context())
+	}))
+
+	t.Run("incoming", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(RequestIDHeader, "abc-123")
+
+		handler.ServeHTTP(w, r)
+		require.Equal(t, "abc-123", requestID)
+		require.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.NotEmpty(t, requestID)
+		require.Equal(t, requestID, w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("logged", func(t *testing.T) {
+		var buf bytes.Buffer
+		log := slog.New(slog.NewTextHandler(&buf, nil))
+
+		// the logger goes first, so the ID is taken from the response header
+		handler := RequestLogger(WithRequestLog(log))(RequestID()(http.NotFoundHandler()))
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(RequestIDHeader, "abc-123")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		require.Contains(t, buf.String(), "request_id=abc-123")
+	})
+
+	t.Run("propagated", func(t *testing.T) {
+		var outgoing string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			outgoing = r.Header.Get(RequestIDHeader)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: PropagateRequestID(nil)}
+
+		r, err := http.NewRequestWithContext(_ctx.WithRequestCtx(t.Context(), "abc-123"), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(r)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, "abc-123", outgoing)
+		require.Empty(t, r.Header.Get(RequestIDHeader))
+	})
+}
+
+func TestRecoverer(t *testing.T) {
+
+	var buf bytes.Buffer
+	var reported *errors.PanicError
+
+	handler := RequestID()(Recoverer(
+		WithRecoverLog(slog.New(slog.NewTextHandler(&buf, nil))),
+		WithPanicHook(func(ctx context.Context, err *errors.PanicError) {
+			reported = err
+		}),
+	)(HandlerWithError(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	})))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIDHeader, "abc-123")
+	handler.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{"message":"Internal Server Error","errors":["internal error"]}`, w.Body.String())
+
+	require.NotNil(t, reported)
+	require.Equal(t, "boom", reported.Value)
+	require.Contains(t, string(reported.Stack), "middleware_test.go")
+
+	require.Contains(t, buf.String(), `err="panic: boom"`)
+	require.Contains(t, buf.String(), "request_id=abc-123")
+	require.Contains(t, buf.String(), "stack=")
+
+	t.Run("abort handler", func(t *testing.T) {
+		handler := Recoverer()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Route is a route registered with its method and pattern, e.g. GET /api/v1/users/{id}.
+type Route struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+func (route Route) String() string {
+	return route.Method + " " + route.Pattern
+}
+
+// anyMethod is the method of routes serving every method, e.g. mounted handlers
+const anyMethod = "*"
+
+// methodsCount is the number of methods chi routes, a route of every one of them serves any method
+const methodsCount = 9
+
+// Routes lists routes of the router, routes of mounted routers included, sorted by pattern.
+// Routes serving every method, e.g. mounted handlers, are listed once with the * method.
+func Routes(router chi.Routes) []Route {
+
+	routes := make([]Route, 0)
+	if router == nil {
+		return routes
+	}
+
+	methods := make(map[string][]string)
+	patterns := make([]string, 0)
+
+	_ = chi.Walk(router, func(method string, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if _, ok := methods[pattern]; !ok {
+			patterns = append(patterns, pattern)
+		}
+		methods[pattern] = append(methods[pattern], method)
+		return nil
+	})
+
+	for _, pattern := range patterns {
+		if len(methods[pattern]) >= methodsCount {
+			routes = append(routes, Route{Method: anyMethod, Pattern: pattern})
+			continue
+		}
+		for _, method := range methods[pattern] {
+			routes = append(routes, Route{Method: method, Pattern: pattern})
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
@@ -3,7 +3,10 @@ package http
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -20,23 +23,49 @@ type Server struct {
 	log    *slog.Logger
 	server *http.Server
 	config Config
-}
 
-func init() {
-	config.AddStructs(ConfigEnv{})
+	started     chan struct{}
+	startedOnce sync.Once
+	serving     atomic.Bool
+	// listener is used instead of listening to the config address when set
+	listener net.Listener
+	// listenFunc listens to the config address, net.Listen by default
+	listenFunc func(network, address string) (net.Listener, error)
+	// timeout bounds request contexts unless it's zero, it's changed on config reload
+	timeout atomic.Int64
+	// pinnedServer is set when the address and header timeout are set by WithServerConfig
+	pinnedServer bool
+	// pinnedRequestTimeout is set when the request timeout is set by WithRequestTimeout
+	pinnedRequestTimeout bool
 }
 
 type ConfigEnv struct {
-	Port    uint16        `env:"HTTP_PORT" env-default:"8080" desc:"HTTP server port"`
-	Timeout time.Duration `env:"HTTP_TIMEOUT" env-default:"15s" desc:"HTTP timeout"`
+	Port    uint16        `env:"HTTP_PORT" env-default:"8080" desc:"HTTP server port" validate:"gte=1"`
+	Timeout time.Duration `env:"HTTP_TIMEOUT" env-default:"15s" desc:"HTTP timeout to read request headers"`
+	// RequestTimeout is opt-in, the deadline cancels streaming, long polling and slow uploads as well
+	RequestTimeout time.Duration `env:"HTTP_REQUEST_TIMEOUT" desc:"Deadline of every HTTP request context, zero disables it"`
 }
 
 func (ConfigEnv) Desc() string {
 	return "http server settings"
 }
 
+// Validate rejects configs the server can't be reloaded with.
+func (conf ConfigEnv) Validate() error {
+	if conf.Timeout < 0 {
+		return errors.New("http timeout can't be negative")
+	}
+	if conf.RequestTimeout < 0 {
+		return errors.New("http request timeout can't be negative")
+	}
+	return nil
+}
+
 type Config struct {
+	// Server.Timeout bounds reading of request headers
 	Server config.Server
+	// RequestTimeout bounds request contexts unless it's zero
+	RequestTimeout time.Duration
 }
 
 type ServerOption func(*Server)
@@ -62,22 +91,27 @@ func NewHttpServer(
 			Port:    envConf.Port,
 			Timeout: envConf.Timeout,
 		},
+		RequestTimeout: envConf.RequestTimeout,
 	}
 
 	srv := &Server{
 		log: log,
 		server: &http.Server{
-			Addr:    config.Server.String(),
-			Handler: handler,
+			Addr: config.Server.String(),
 		},
-		config: config,
+		config:  config,
+		started: make(chan struct{}),
 	}
+	srv.server.Handler = srv.withTimeout(handler)
 
 	for _, opt := range opts {
 		opt(srv)
 	}
 
-	if err := validateConfig(config); err != nil {
+	srv.server.ReadHeaderTimeout = srv.config.Server.Timeout
+	srv.timeout.Store(int64(srv.config.RequestTimeout))
+
+	if err := validateConfig(srv.config); err != nil {
 		return nil, errors.Wrap(err, "failed to validate http app config")
 	}
 
@@ -91,7 +125,17 @@ func (a *Server) Start(_ context.Context) error {
 
 	log.Info("starting server")
 
-	if err := a.server.ListenAndServe(); err != nil {
+	listener, err := a.listen()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	a.startedOnce.Do(func() { close(a.started) })
+
+	a.serving.Store(true)
+	defer a.serving.Store(false)
+
+	if err := a.server.Serve(listener); err != nil {
 		if !errors.Is(err, http.ErrServerClosed) {
 			return errors.Wrap(err, op)
 		}
@@ -100,6 +144,134 @@ func (a *Server) Start(_ context.Context) error {
 	return nil
 }
 
+// WithServerConfig overrides the server address and header timeout read from the environment.
+func WithServerConfig(conf config.Server) ServerOption {
+	return func(srv *Server) {
+		srv.config.Server = conf
+		srv.server.Addr = conf.String()
+		srv.pinnedServer = true
+	}
+}
+
+// WithServerLog replaces the logger the server logs with, logs.SetupLogger by default.
+func WithServerLog(log *slog.Logger) ServerOption {
+	return func(srv *Server) {
+		if log != nil {
+			srv.log = log.With(appComponent())
+		}
+	}
+}
+
+// WithRequestTimeout bounds every request context by the timeout, zero doesn't bound them.
+// Handlers streaming responses, long polling or reading slow uploads are cancelled by the deadline as well,
+// so it's off by default. It overrides HTTP_REQUEST_TIMEOUT, which isn't reloaded then.
+func WithRequestTimeout(timeout time.Duration) ServerOption {
+	return func(srv *Server) {
+		srv.config.RequestTimeout = timeout
+		srv.pinnedRequestTimeout = true
+	}
+}
+
+// WithResponseHeaders sets the headers on every response.
+func WithResponseHeaders(headers http.Header) ServerOption {
+	return func(srv *Server) {
+		next := srv.server.Handler
+		srv.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for key, values := range headers {
+				for _, value := range values {
+					w.Header().Add(key, value)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithListener makes the server accept connections on the listener instead of the config address.
+func WithListener(listener net.Listener) ServerOption {
+	return func(srv *Server) {
+		if listener != nil {
+			srv.listener = listener
+		}
+	}
+}
+
+// WithListenFunc makes the server listen to the config address with the function,
+// e.g. to take over a listener inherited from the previous process.
+func WithListenFunc(listen func(network, address string) (net.Listener, error)) ServerOption {
+	return func(srv *Server) {
+		if listen != nil {
+			srv.listenFunc = listen
+		}
+	}
+}
+
+func (a *Server) listen() (net.Listener, error) {
+	if a.listener != nil {
+		return a.listener, nil
+	}
+	if a.listenFunc != nil {
+		return a.listenFunc("tcp", a.server.Addr)
+	}
+	return net.Listen("tcp", a.server.Addr)
+}
+
+// withTimeout bounds request contexts by the request timeout, zero timeout doesn't bound them.
+func (a *Server) withTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if timeout := time.Duration(a.timeout.Load()); timeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ReloadConfig implements config.Reloadable, the request timeout is applied without restart.
+func (a *Server) ReloadConfig() any {
+	return ConfigEnv{}
+}
+
+// Reload applies the request timeout of the ConfigEnv unless it's set by WithRequestTimeout.
+// Changes of the address and header timeout require restart, they are ignored when set by WithServerConfig.
+func (a *Server) Reload(conf any) error {
+	envConf, ok := conf.(ConfigEnv)
+	if !ok {
+		return errors.Errorf("unexpected http config type %T", conf)
+	}
+
+	if !a.pinnedServer {
+		if envConf.Port != a.config.Server.Port {
+			a.log.Warn("http port change requires restart", slog.Any("port", envConf.Port))
+		}
+		if envConf.Timeout != a.config.Server.Timeout {
+			a.log.Warn("http timeout change requires restart", slog.Duration("timeout", envConf.Timeout))
+		}
+	}
+
+	if a.pinnedRequestTimeout {
+		return nil
+	}
+
+	a.timeout.Store(int64(envConf.RequestTimeout))
+	a.log.Info("http request timeout reloaded", slog.Duration("timeout", envConf.RequestTimeout))
+	return nil
+}
+
+// Started returns a channel which is closed once the server listens to its address.
+func (a *Server) Started() <-chan struct{} {
+	return a.started
+}
+
+// CheckHealth reports an error unless the server accepts connections.
+func (a *Server) CheckHealth(_ context.Context) error {
+	if !a.serving.Load() {
+		return errors.New("http server is not serving")
+	}
+	return nil
+}
+
 func (a *Server) Stop(ctx context.Context) error {
 
 	const op = "http.Server.Stop"
@@ -107,7 +279,10 @@ func (a *Server) Stop(ctx context.Context) error {
 	a.log.Info("stopping server", logs.Operation(op), slog.Any("port", a.config.Server.Port))
 
 	if err := a.server.Shutdown(ctx); err != nil {
-		a.log.Error("server shutdown failed", logs.Error(err))
+		a.log.Error("server shutdown failed, closing connections", logs.Error(err))
+		if err := a.server.Close(); err != nil {
+			return errors.Wrap(err, op)
+		}
 	}
 	return nil
 }
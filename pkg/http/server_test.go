@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vishenosik/gocherry/pkg/config"
+)
+
+func TestRequestTimeout(t *testing.T) {
+
+	var (
+		deadline time.Time
+		bounded  bool
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, bounded = r.Context().Deadline()
+	})
+
+	serve := func(srv *Server) {
+		srv.server.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		t.Setenv("HTTP_TIMEOUT", "5s")
+
+		srv, err := NewHttpServer(handler)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Second, srv.server.ReadHeaderTimeout)
+
+		serve(srv)
+		require.False(t, bounded)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("HTTP_REQUEST_TIMEOUT", "1m")
+
+		srv, err := NewHttpServer(handler)
+		require.NoError(t, err)
+
+		serve(srv)
+		require.True(t, bounded)
+		require.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+		require.NoError(t, srv.Reload(ConfigEnv{Port: srv.config.Server.Port, Timeout: srv.config.Server.Timeout}))
+		serve(srv)
+		require.False(t, bounded)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		srv, err := NewHttpServer(handler, WithRequestTimeout(time.Minute))
+		require.NoError(t, err)
+
+		require.NoError(t, srv.Reload(ConfigEnv{}))
+		serve(srv)
+		require.True(t, bounded)
+	})
+
+	t.Run("server config", func(t *testing.T) {
+		srv, err := NewHttpServer(handler, WithServerConfig(config.Server{Port: 8081}))
+		require.NoError(t, err)
+
+		// the address is pinned, the request timeout is reloaded still
+		require.NoError(t, srv.Reload(ConfigEnv{Port: 8080, RequestTimeout: time.Minute}))
+		serve(srv)
+		require.True(t, bounded)
+	})
+}
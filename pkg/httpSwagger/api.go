@@ -10,15 +10,13 @@ import (
 	"github.com/vishenosik/gocherry/pkg/logs"
 )
 
-func init() {
-	config.AddStructs(&ConfigEnv{})
-}
-
+// ConfigEnv is the swagger config, register it with the app to check and list it,
+// e.g. gocherry.WithConfigs(httpSwagger.ConfigEnv{}).
 type ConfigEnv struct {
-	// HTTP server port
-	Port uint16 `env:"HTTP_PORT" env-default:"8080" desc:"-"`
+	// HTTP server port, HTTP_PORT of the http server is read unless SWAGGER_PORT is set
+	Port uint16 `env:"SWAGGER_PORT" env-deprecated:"HTTP_PORT" env-default:"8080" desc:"-"`
 	// HTTP host
-	Host string `env:"SWAGGER_HTTP_HOST" env-default:"localhost:8080" desc:"HTTP host"`
+	Host string `env:"SWAGGER_HOST" env-deprecated:"SWAGGER_HTTP_HOST" env-default:"localhost:8080" desc:"HTTP host"`
 	// Enable swagger
 	Enable bool `env:"SWAGGER_ENABLE" env-default:"false" desc:"Enable swagger"`
 }
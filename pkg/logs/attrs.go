@@ -18,6 +18,8 @@ const (
 	AttrUserID       = "user_id" // Assuming User struct has field "ID"
 	AttrAppID        = "app_id"  // Assuming App struct has field
 	AttrAppComponent = "app_component"
+	AttrRequestID    = "request_id"
+	AttrStack        = "stack"
 )
 
 func Error(err error) slog.Attr {
@@ -51,3 +53,11 @@ func AppID(appID string) slog.Attr {
 func AppComponent(component string) slog.Attr {
 	return slog.String(AttrAppComponent, component)
 }
+
+func RequestID(requestID string) slog.Attr {
+	return slog.String(AttrRequestID, requestID)
+}
+
+func Stack(stack []byte) slog.Attr {
+	return slog.String(AttrStack, string(stack))
+}
@@ -140,7 +140,7 @@ func WithWriter(writer io.Writer) HandlerOption {
 	}
 }
 
-func WithLevel(level slog.Level) HandlerOption {
+func WithLevel(level slog.Leveler) HandlerOption {
 	return func(h *Handler) {
 		handlerOptions := &slog.HandlerOptions{
 			Level: level,
@@ -22,19 +22,29 @@ const (
 var (
 	once sync.Once
 	glob *slog.Logger
+	// globLevel is the level of the global logger, it's changed by SetLevel
+	globLevel = new(slog.LevelVar)
 )
 
-func init() {
-	config.AddStructs(EnvConfig{})
+// EnvConfig is the logger config, every gocherry app registers it.
+type EnvConfig struct {
+	// Env isn't validated, environments other than dev, prod and test are logged like prod
+	Env   string `env:"ENV" env-default:"dev" desc:"The environment in which the application is running"`
+	Level string `env:"LOG_LEVEL" desc:"Log level: debug, info, warn or error, the environment default if empty"`
 }
 
-type EnvConfig struct {
-	Env string `env:"ENV" env-default:"dev" desc:"The environment in which the application is running"`
+// Validate rejects unknown log levels.
+func (conf EnvConfig) Validate() error {
+	_, err := parseLevel(conf.Level)
+	return err
 }
 
 type Config struct {
-	Env        string `validate:"oneof=dev prod test"`
+	// Env isn't validated, environments other than dev, prod and test are logged like prod
+	Env        string
 	Marshaller string `validate:"oneof=json yaml"`
+	// Level overrides the environment default level
+	Level slog.Leveler
 }
 
 func (conf Config) Validate() error {
@@ -53,20 +63,70 @@ func SetupLogger() *slog.Logger {
 	}
 
 	once.Do(func() {
+		globLevel.Set(defaultLevel(envConf.Env))
+		if err := SetLevel(envConf.Level); err != nil {
+			log.Println(errors.Wrap(err, "setup logger"))
+		}
+
 		glob = SetupLoggerConf(Config{
-			Env: envConf.Env,
+			Env:   envConf.Env,
+			Level: globLevel,
 		})
 	})
 
 	return glob
 }
 
+// SetLevel changes the level of the logger returned by SetupLogger,
+// the empty level resets it to the environment default.
+func SetLevel(levelName string) error {
+	if levelName == "" {
+		var envConf EnvConfig
+		_ = config.ReadConfigEnv(&envConf)
+		globLevel.Set(defaultLevel(envConf.Env))
+		return nil
+	}
+
+	parsed, err := parseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	globLevel.Set(parsed)
+	return nil
+}
+
+func parseLevel(levelName string) (slog.Level, error) {
+	var parsed slog.Level
+	if levelName == "" {
+		return parsed, nil
+	}
+	if err := parsed.UnmarshalText([]byte(levelName)); err != nil {
+		return parsed, errors.Wrap(err, "invalid log level")
+	}
+	return parsed, nil
+}
+
+// defaultLevel returns the level of the environment, custom environments are logged like prod.
+func defaultLevel(env string) slog.Level {
+	switch env {
+	case EnvDev, "":
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
 func SetupLoggerConf(conf Config) *slog.Logger {
 
 	if err := conf.Validate(); err != nil {
 		log.Println(err)
 	}
 
+	var lvl slog.Leveler = defaultLevel(conf.Env)
+	if conf.Level != nil {
+		lvl = conf.Level
+	}
+
 	var handler slog.Handler
 
 	switch conf.Env {
@@ -74,17 +134,18 @@ func SetupLoggerConf(conf Config) *slog.Logger {
 	case EnvProd:
 		handler = slog.NewJSONHandler(
 			os.Stdout,
-			&slog.HandlerOptions{Level: slog.LevelInfo},
+			&slog.HandlerOptions{Level: lvl},
 		)
 
 	case EnvTest:
 		handler = slog.NewJSONHandler(
 			io.Discard,
-			&slog.HandlerOptions{Level: slog.LevelInfo},
+			&slog.HandlerOptions{Level: lvl},
 		)
 
 	case EnvDev:
 		handler = NewHandler(
+			WithLevel(lvl),
 			WithYamlMarshaller(),
 			WithNumbersHighlight(colors.Blue),
 			WithKeyWordsHighlight(map[string]colors.ColorCode{
@@ -96,7 +157,7 @@ func SetupLoggerConf(conf Config) *slog.Logger {
 	default:
 		handler = slog.NewJSONHandler(
 			os.Stdout,
-			&slog.HandlerOptions{Level: slog.LevelDebug},
+			&slog.HandlerOptions{Level: lvl},
 		)
 	}
 
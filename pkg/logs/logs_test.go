@@ -0,0 +1,16 @@
+package logs
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_defaultLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, defaultLevel(EnvDev))
+	assert.Equal(t, slog.LevelInfo, defaultLevel(EnvProd))
+	assert.Equal(t, slog.LevelInfo, defaultLevel(EnvTest))
+	// custom environments are logged like prod
+	assert.Equal(t, slog.LevelInfo, defaultLevel("staging"))
+}
@@ -0,0 +1,64 @@
+package module
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/vishenosik/gocherry/pkg/grpc"
+)
+
+// Module packages config structs, services, closers, http routes and grpc services
+// of a feature, so an app is assembled from modules instead of wiring them by hand.
+type Module interface {
+	// Name identifies the module in logs and errors.
+	Name() string
+	// Configs returns config structs the module reads, they are listed by config flags of the app.
+	Configs() []any
+	// Setup constructs components of the module and registers them in the app.
+	Setup(app Registrar) error
+}
+
+// Registrar is the part of the app available to modules during setup.
+type Registrar interface {
+	// Logger returns the app logger.
+	Logger() *slog.Logger
+	// AddServices registers services and closers.
+	AddServices(services ...any)
+	// Provide makes values available to modules set up later.
+	Provide(values ...any)
+	// Lookup sets target, a non-nil pointer, to the first provided value assignable to it.
+	Lookup(target any) bool
+	// MountHTTP mounts the handler to the app http server under the prefix.
+	MountHTTP(prefix string, handler http.Handler)
+	// AddGrpcServices registers services in the app grpc server.
+	AddGrpcServices(services ...grpc.GrpcService)
+	// OnStart registers hooks run before services are started.
+	OnStart(hooks ...func(ctx context.Context) error)
+}
+
+type module struct {
+	name    string
+	configs []any
+	setup   func(app Registrar) error
+}
+
+// New creates a module from its name, config structs and setup function.
+func New(name string, configs []any, setup func(app Registrar) error) Module {
+	return &module{
+		name:    name,
+		configs: configs,
+		setup:   setup,
+	}
+}
+
+func (m *module) Name() string { return m.name }
+
+func (m *module) Configs() []any { return m.configs }
+
+func (m *module) Setup(app Registrar) error {
+	if m.setup == nil {
+		return nil
+	}
+	return m.setup(app)
+}
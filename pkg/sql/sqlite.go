@@ -3,8 +3,11 @@ package sql
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jmoiron/sqlx"
@@ -12,18 +15,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/pressly/goose/v3"
 	"github.com/vishenosik/gocherry/pkg/config"
+	"github.com/vishenosik/gocherry/pkg/module"
 )
 
-func init() {
-	config.AddStructs(SqliteConfigEnv{})
-}
-
 type SqliteConfig struct {
 	StorePath string `validate:"required"`
 }
 
 type SqliteConfigEnv struct {
-	StorePath string `env:"SQLITE_STORE_PATH" env-default:"./storage/store.db" desc:"A path to sqlite store file"`
+	StorePath string `env:"SQLITE_STORE_PATH" env-default:"./storage/store.db" desc:"A path to sqlite store file" validate:"required"`
 }
 
 func (SqliteConfigEnv) Desc() string {
@@ -77,32 +77,123 @@ func NewSqliteStoreConfig(conf SqliteConfig, opts ...SqliteStoreOption) (*Sqlite
 }
 
 func (ss *SqliteStore) Close(_ context.Context) error {
+	if ss.db == nil {
+		return nil
+	}
 	return ss.db.Close()
 }
 
-func (ss *SqliteStore) Open(_ context.Context) (*sqlx.DB, error) {
+// DB returns the store connection, it's nil until the store is opened.
+func (ss *SqliteStore) DB() *sqlx.DB {
+	return ss.db
+}
+
+// CheckHealth pings the opened sqlite store.
+func (ss *SqliteStore) CheckHealth(ctx context.Context) error {
+	if ss.db == nil {
+		return errors.New("sqlite store is not opened")
+	}
+	return ss.db.PingContext(ctx)
+}
+
+func (ss *SqliteStore) Open(ctx context.Context) (*sqlx.DB, error) {
+	db, err := ss.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	if ss.migrationsFS == nil {
+		return db, nil
+	}
+
+	if err := ss.MigrateUp(ctx); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (ss *SqliteStore) connect() (*sqlx.DB, error) {
+	if ss.db != nil {
+		return ss.db, nil
+	}
+
 	db, err := sqlx.Open("sqlite3", ss.storePath)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to connect to sqlite")
 	}
 
 	ss.db = db
+	return db, nil
+}
 
+func (ss *SqliteStore) migrations() (*goose.Provider, error) {
 	if ss.migrationsFS == nil {
-		return db, nil
+		return nil, errors.New("migrations are not set")
+	}
+
+	db, err := ss.connect()
+	if err != nil {
+		return nil, err
 	}
 
-	goose.SetLogger(goose.NopLogger())
-	goose.SetBaseFS(ss.migrationsFS)
+	migrations, err := fs.Sub(ss.migrationsFS, ss.migrationsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read migrations")
+	}
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db.DB, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set sqlite migrations: %w", err)
+	}
+	return provider, nil
+}
 
-	if err := goose.SetDialect("sqlite"); err != nil {
-		return nil, fmt.Errorf("failed to set sqlite: %w", err)
+// MigrateUp applies pending migrations, the store is connected if it isn't opened.
+func (ss *SqliteStore) MigrateUp(ctx context.Context) error {
+	provider, err := ss.migrations()
+	if err != nil {
+		return err
 	}
 
-	if err := goose.Up(db.DB, ss.migrationsPath); err != nil {
-		return nil, errors.Wrap(err, "failed to run migrations up")
+	if _, err := provider.Up(ctx); err != nil {
+		return errors.Wrap(err, "failed to run migrations up")
 	}
-	return db, nil
+	return nil
+}
+
+// MigrateDown rolls the latest applied migration back.
+func (ss *SqliteStore) MigrateDown(ctx context.Context) error {
+	provider, err := ss.migrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := provider.Down(ctx); err != nil {
+		return errors.Wrap(err, "failed to run migration down")
+	}
+	return nil
+}
+
+// MigrateStatus writes the state of every migration, one per line.
+func (ss *SqliteStore) MigrateStatus(ctx context.Context, writer io.Writer) error {
+	provider, err := ss.migrations()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get migrations status")
+	}
+
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%-8s %-25s %s\n", status.State, appliedAt, path.Base(status.Source.Path))
+	}
+	return nil
 }
 
 func WithMigration(
@@ -132,3 +223,21 @@ func SqliteUniqueError(err error, mapper map[string]string) error {
 
 	return nil
 }
+
+// SqliteModule opens the sqlite store and runs migrations from fs path before services are started.
+// The store is provided as *SqliteStore and closed when the app stops.
+func SqliteModule(fs fs.FS, path string, opts ...SqliteStoreOption) module.Module {
+	return module.New("sqlite store", []any{SqliteConfigEnv{}}, func(app module.Registrar) error {
+		store, err := NewSqliteStore(append(opts, WithMigration(fs, path))...)
+		if err != nil {
+			return err
+		}
+		app.Provide(store)
+		app.AddServices(store)
+		app.OnStart(func(ctx context.Context) error {
+			_, err := store.Open(ctx)
+			return err
+		})
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+// Package systemd sends service state notifications to systemd, see sd_notify(3).
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	envNotifySocket = "NOTIFY_SOCKET"
+	envWatchdogUsec = "WATCHDOG_USEC"
+	envWatchdogPid  = "WATCHDOG_PID"
+)
+
+// Notification states.
+const (
+	Ready     = "READY=1"
+	Stopping  = "STOPPING=1"
+	Reloading = "RELOADING=1"
+	Watchdog  = "WATCHDOG=1"
+)
+
+// Status describes the service state in a free form shown by systemctl status.
+func Status(status string) string {
+	return "STATUS=" + status
+}
+
+// MainPID tells systemd the main process of the service is changed, e.g. after an upgrade.
+func MainPID(pid int) string {
+	return "MAINPID=" + strconv.Itoa(pid)
+}
+
+// Notifier sends notifications to the socket set by systemd in $NOTIFY_SOCKET.
+type Notifier struct {
+	addr *net.UnixAddr
+}
+
+// NewNotifier returns nil unless the process is started by systemd with notify access.
+// Notifications of the nil notifier are dropped.
+func NewNotifier() *Notifier {
+	socket := os.Getenv(envNotifySocket)
+	if socket == "" {
+		return nil
+	}
+
+	// the abstract socket namespace
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	return &Notifier{addr: &net.UnixAddr{Name: socket, Net: "unixgram"}}
+}
+
+// Notify sends the states in one message.
+func (n *Notifier) Notify(states ...string) error {
+	if n == nil || len(states) == 0 {
+		return nil
+	}
+
+	conn, err := net.DialUnix(n.addr.Net, nil, n.addr)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to notify socket")
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(states, "\n"))); err != nil {
+		return errors.Wrap(err, "failed to notify systemd")
+	}
+	return nil
+}
+
+// WatchdogInterval returns the interval set by WatchdogSec= of the unit.
+// Watchdog notifications are expected at least twice as often.
+func WatchdogInterval() (time.Duration, bool) {
+
+	usec, err := strconv.ParseInt(os.Getenv(envWatchdogUsec), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	if pid := os.Getenv(envWatchdogPid); pid != "" && pid != fmt.Sprint(os.Getpid()) {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
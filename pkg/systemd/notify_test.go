@@ -0,0 +1,73 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func notifySocket(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv(envNotifySocket, path)
+	return conn
+}
+
+func TestNotifier(t *testing.T) {
+
+	t.Run("notify", func(t *testing.T) {
+		conn := notifySocket(t)
+
+		notifier := NewNotifier()
+		require.NotNil(t, notifier)
+		require.NoError(t, notifier.Notify(Ready, Status("ready")))
+
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, "READY=1\nSTATUS=ready", string(buf[:n]))
+	})
+
+	t.Run("no socket", func(t *testing.T) {
+		t.Setenv(envNotifySocket, "")
+
+		notifier := NewNotifier()
+		require.Nil(t, notifier)
+		require.NoError(t, notifier.Notify(Ready))
+	})
+}
+
+func TestWatchdogInterval(t *testing.T) {
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv(envWatchdogUsec, "3000000")
+		t.Setenv(envWatchdogPid, strconv.Itoa(os.Getpid()))
+
+		interval, ok := WatchdogInterval()
+		require.True(t, ok)
+		require.Equal(t, 3*time.Second, interval)
+	})
+
+	t.Run("other process", func(t *testing.T) {
+		t.Setenv(envWatchdogUsec, "3000000")
+		t.Setenv(envWatchdogPid, "1")
+
+		_, ok := WatchdogInterval()
+		require.False(t, ok)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(envWatchdogUsec, "")
+
+		_, ok := WatchdogInterval()
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,52 @@
+package gocherry
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/vishenosik/gocherry/pkg/config"
+	"github.com/vishenosik/gocherry/pkg/errors"
+	"github.com/vishenosik/gocherry/pkg/logs"
+)
+
+// WithoutConfigPreflight skips the config check of NewApp, e.g. when configs are passed
+// to constructors directly instead of being read from the environment.
+func WithoutConfigPreflight() AppOption {
+	return func(app *App) {
+		app.skipPreflight = true
+	}
+}
+
+// preflight checks configs of the app components and the registered ones before anything is started,
+// so a missing or invalid variable fails NewApp instead of a constructor in the middle of startup.
+// Unknown and deprecated variables are logged only.
+func (app *App) preflight() error {
+
+	if app.skipPreflight {
+		return nil
+	}
+
+	report, err := config.Preflight(slices.Concat(app.configs.Structs(), config.Structs())...)
+	if err != nil {
+		return errors.Wrap(err, "config preflight")
+	}
+
+	for _, issue := range report.Deprecated() {
+		app.Log.Warn("deprecated config variable",
+			slog.String("variable", issue.Env),
+			slog.String("section", issue.Section),
+			slog.String("message", issue.Message),
+		)
+	}
+
+	if unknown := report.Unknown(); len(unknown) > 0 {
+		app.Log.Warn("unknown config variables", slog.Any("variables", unknown))
+	}
+
+	if err := report.Err(); err != nil {
+		app.Log.Error("config preflight failed", logs.Error(err))
+		return err
+	}
+
+	return nil
+}
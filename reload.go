@@ -0,0 +1,95 @@
+package gocherry
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/vishenosik/gocherry/pkg/config"
+	"github.com/vishenosik/gocherry/pkg/logs"
+)
+
+const (
+	defaultConfigWatchInterval = 5 * time.Second
+)
+
+// WithConfigWatch sets how often App.Run checks the env and config files for changes, zero disables the check.
+func WithConfigWatch(interval time.Duration) AppOption {
+	return func(app *App) {
+		if interval >= 0 {
+			app.configWatch = interval
+		}
+	}
+}
+
+// Reloader returns the registry of components App.Reload hands configs to.
+// Services and closers implementing config.Reloadable are registered on their own,
+// use config.OnReload to register functions.
+func (app *App) Reloader() *config.Reloader {
+	return app.reloader
+}
+
+// Reload re-reads the config file, the env file and the environment, validates the configs and hands
+// the changed ones to registered components. Invalid configs are rejected and logged,
+// components keep running with the previous ones.
+func (app *App) Reload() error {
+
+	applied, err := app.reloader.Reload()
+	if err != nil {
+		app.Log.Error("config reload rejected", logs.Error(err))
+		return err
+	}
+
+	app.Log.Info("config reloaded", slog.Any("configs", applied))
+	return nil
+}
+
+// watchConfig reloads configs once the env or config file is changed until the app is stopping.
+func (app *App) watchConfig() {
+
+	if app.configWatch <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(app.configWatch)
+	defer ticker.Stop()
+
+	last := configVersion()
+
+	for {
+		select {
+		case <-app.stopping:
+			return
+		case <-ticker.C:
+			if current := configVersion(); current != last {
+				last = current
+				_ = app.Reload()
+			}
+		}
+	}
+}
+
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// configVersion identifies contents of the env and config files.
+func configVersion() [2]fileVersion {
+	return [2]fileVersion{
+		fileVersionOf(config.EnvFile),
+		fileVersionOf(config.ConfigFile()),
+	}
+}
+
+// fileVersionOf identifies the file contents, the missing file has zero version.
+func fileVersionOf(path string) fileVersion {
+	if path == "" {
+		return fileVersion{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileVersion{}
+	}
+	return fileVersion{modTime: info.ModTime(), size: info.Size()}
+}
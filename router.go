@@ -0,0 +1,214 @@
+package gocherry
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/swaggo/swag/v2"
+
+	"github.com/vishenosik/gocherry/pkg/api"
+	_http "github.com/vishenosik/gocherry/pkg/http"
+	"github.com/vishenosik/gocherry/pkg/httpSwagger"
+)
+
+// Router builds the public http routes of the app: route groups with their middleware stacks,
+// versioned api groups and swagger. The app serves health routes next to it, see WithRouter.
+//
+//	router := gocherry.NewRouter(gocherry.WithSwagger(docs.SwaggerInfo))
+//	router.Use(_http.RequestLogger())
+//
+//	v1 := router.Version(1)
+//	v1.Get("/users/{id}", getUser)
+//	v1.Group("/admin", auth).Post("/users", createUser)
+//
+//	app, err := gocherry.NewApp(gocherry.WithRouter(router))
+type Router struct {
+	*RouteGroup
+	swagger *swag.Spec
+}
+
+type RouterOption = func(*Router)
+
+// NewRouter returns a router without routes.
+func NewRouter(opts ...RouterOption) *Router {
+	router := &Router{RouteGroup: newRouteGroup(_http.BlankRoute)}
+	for _, opt := range opts {
+		opt(router)
+	}
+	return router
+}
+
+// WithSwagger serves the swagger UI of the spec at /swagger/ once it's enabled by SWAGGER_ENABLE.
+func WithSwagger(spec *swag.Spec) RouterOption {
+	return func(router *Router) {
+		if spec != nil {
+			router.swagger = spec
+		}
+	}
+}
+
+// Version returns the group of the api version, e.g. /api/v2 for 2, creating it if needed.
+// Responses of api groups are JSON, see _http.SetHeaders.
+func (router *Router) Version(version uint8, middlewares ..._http.Middleware) *RouteGroup {
+	prefix := api.ApiVersion(version)
+	if group := router.group(prefix); group != nil {
+		return group.Use(middlewares...)
+	}
+	return router.Group(prefix, append([]_http.Middleware{_http.SetHeaders()}, middlewares...)...)
+}
+
+// Configs returns config structs of the routes, e.g. the swagger one.
+func (router *Router) Configs() []any {
+	if router.swagger == nil {
+		return nil
+	}
+	return []any{httpSwagger.ConfigEnv{}}
+}
+
+// Handler builds the routes. Routes added afterwards are served by handlers built later only.
+func (router *Router) Handler() http.Handler {
+	return router.mux()
+}
+
+// Routes lists routes with their methods and patterns, e.g. for debugging.
+func (router *Router) Routes() []_http.Route {
+	return _http.Routes(router.mux())
+}
+
+func (router *Router) mux() *chi.Mux {
+	mux := chi.NewRouter()
+	mux.Use(router.middlewares...)
+	if router.swagger != nil {
+		httpSwagger.NewSwagger(router.swagger).Routers(mux)
+	}
+	router.RouteGroup.routes(mux)
+	return mux
+}
+
+// Routes lists routes of the app http server with their methods and patterns, health routes included.
+func (app *App) Routes() []_http.Route {
+	if app.httpRouter == nil {
+		return make([]_http.Route, 0)
+	}
+	return _http.Routes(app.httpRouter)
+}
+
+// RouteGroup is a set of routes sharing a prefix and a middleware stack. Nested groups
+// are served with middlewares of their parents first.
+type RouteGroup struct {
+	prefix      string
+	middlewares []_http.Middleware
+	handlers    []func(chi.Router)
+	groups      []*RouteGroup
+}
+
+func newRouteGroup(prefix string) *RouteGroup {
+	return &RouteGroup{prefix: cleanPrefix(prefix)}
+}
+
+// Use appends middlewares to the stack of the group.
+func (g *RouteGroup) Use(middlewares ..._http.Middleware) *RouteGroup {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
+
+// Group returns the nested group of the prefix, creating it if needed, and appends middlewares to its stack.
+// A group of the blank prefix shares the prefix of its parent, so its middlewares wrap some routes only.
+func (g *RouteGroup) Group(prefix string, middlewares ..._http.Middleware) *RouteGroup {
+	group := g.group(prefix)
+	if group == nil {
+		group = newRouteGroup(prefix)
+		g.groups = append(g.groups, group)
+	}
+	return group.Use(middlewares...)
+}
+
+// Handle routes requests of the method and pattern relative to the group prefix.
+func (g *RouteGroup) Handle(method, pattern string, handler http.Handler) *RouteGroup {
+	g.handlers = append(g.handlers, func(r chi.Router) {
+		r.Method(method, pattern, handler)
+	})
+	return g
+}
+
+// HandleFunc routes requests of the method and pattern relative to the group prefix.
+func (g *RouteGroup) HandleFunc(method, pattern string, handler http.HandlerFunc) *RouteGroup {
+	return g.Handle(method, pattern, handler)
+}
+
+// Get routes GET requests of the pattern relative to the group prefix.
+func (g *RouteGroup) Get(pattern string, handler http.HandlerFunc) *RouteGroup {
+	return g.Handle(http.MethodGet, pattern, handler)
+}
+
+// Post routes POST requests of the pattern relative to the group prefix.
+func (g *RouteGroup) Post(pattern string, handler http.HandlerFunc) *RouteGroup {
+	return g.Handle(http.MethodPost, pattern, handler)
+}
+
+// Put routes PUT requests of the pattern relative to the group prefix.
+func (g *RouteGroup) Put(pattern string, handler http.HandlerFunc) *RouteGroup {
+	return g.Handle(http.MethodPut, pattern, handler)
+}
+
+// Patch routes PATCH requests of the pattern relative to the group prefix.
+func (g *RouteGroup) Patch(pattern string, handler http.HandlerFunc) *RouteGroup {
+	return g.Handle(http.MethodPatch, pattern, handler)
+}
+
+// Delete routes DELETE requests of the pattern relative to the group prefix.
+func (g *RouteGroup) Delete(pattern string, handler http.HandlerFunc) *RouteGroup {
+	return g.Handle(http.MethodDelete, pattern, handler)
+}
+
+// Mount serves every request under the pattern with the handler, e.g. a router built elsewhere.
+func (g *RouteGroup) Mount(pattern string, handler http.Handler) *RouteGroup {
+	g.handlers = append(g.handlers, func(r chi.Router) {
+		r.Mount(pattern, handler)
+	})
+	return g
+}
+
+// Routers adds routes by functions of packages setting routes up themselves,
+// e.g. httpSwagger.Swagger.Routers.
+func (g *RouteGroup) Routers(routers ...func(chi.Router)) *RouteGroup {
+	g.handlers = append(g.handlers, routers...)
+	return g
+}
+
+// group returns the nested group of the prefix if any.
+func (g *RouteGroup) group(prefix string) *RouteGroup {
+	prefix = cleanPrefix(prefix)
+	for _, group := range g.groups {
+		if group.prefix == prefix {
+			return group
+		}
+	}
+	return nil
+}
+
+// routes sets routes of the group and nested ones up, middlewares of the group are set by the caller.
+func (g *RouteGroup) routes(r chi.Router) {
+
+	for _, handler := range g.handlers {
+		handler(r)
+	}
+
+	for _, group := range g.groups {
+		build := func(r chi.Router) {
+			r.Use(group.middlewares...)
+			group.routes(r)
+		}
+		if group.prefix == _http.BlankRoute {
+			r.Group(build)
+			continue
+		}
+		r.Route(group.prefix, build)
+	}
+}
+
+// cleanPrefix returns the prefix with a leading slash and without a trailing one, e.g. /api/v1.
+func cleanPrefix(prefix string) string {
+	return "/" + strings.Trim(prefix, "/")
+}
@@ -0,0 +1,169 @@
+package gocherry
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/swaggo/swag/v2"
+
+	"github.com/vishenosik/gocherry/pkg/errors"
+	_http "github.com/vishenosik/gocherry/pkg/http"
+	"github.com/vishenosik/gocherry/pkg/httpSwagger"
+)
+
+func TestRouter(t *testing.T) {
+
+	// header appends the value to the X-Test header, so the order of middlewares is seen
+	header := func(value string) _http.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Test", value)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	get := func(handler http.Handler, route string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, route, nil))
+		return w
+	}
+
+	newRouter := func() *Router {
+		router := NewRouter()
+		router.Use(header("root"))
+		router.Get("/ping", ok)
+
+		v1 := router.Version(1, header("v1"))
+		v1.Get("/users/{id}", ok)
+		v1.Group("/admin", header("admin")).Post("/users", ok)
+		v1.Group("", header("inline")).Delete("/users/{id}", ok)
+
+		router.Version(2).Get("/users/{id}", ok)
+		router.Mount("/legacy", http.NotFoundHandler())
+		return router
+	}
+
+	t.Run("routes", func(t *testing.T) {
+		require.Equal(t, []_http.Route{
+			{Method: http.MethodPost, Pattern: "/api/v1/admin/users"},
+			{Method: http.MethodDelete, Pattern: "/api/v1/users/{id}"},
+			{Method: http.MethodGet, Pattern: "/api/v1/users/{id}"},
+			{Method: http.MethodGet, Pattern: "/api/v2/users/{id}"},
+			{Method: "*", Pattern: "/legacy/*"},
+			{Method: http.MethodGet, Pattern: "/ping"},
+		}, newRouter().Routes())
+	})
+
+	t.Run("middlewares", func(t *testing.T) {
+		handler := newRouter().Handler()
+
+		w := get(handler, "/api/v1/users/1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []string{"root", "v1"}, w.Header().Values("X-Test"))
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/users", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []string{"root", "v1", "admin"}, w.Header().Values("X-Test"))
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/users/1", nil))
+		require.Equal(t, []string{"root", "v1", "inline"}, w.Header().Values("X-Test"))
+
+		w = get(handler, "/ping")
+		require.Equal(t, []string{"root"}, w.Header().Values("X-Test"))
+		require.Empty(t, w.Header().Get("Content-Type"))
+	})
+
+	t.Run("groups are reused", func(t *testing.T) {
+		router := NewRouter()
+		router.Version(1).Get("/a", ok)
+		router.Group("/api/v1/").Get("/b", ok)
+
+		w := get(router.Handler(), "/api/v1/b")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []string{"application/json"}, w.Header().Values("Content-Type"))
+	})
+
+	t.Run("app", func(t *testing.T) {
+		router := NewRouter(WithSwagger(&swag.Spec{}))
+		router.Version(1).Get("/users/{id}", ok)
+
+		app, err := NewApp(WithRouter(router))
+		require.NoError(t, err)
+
+		require.Contains(t, app.Configs(), httpSwagger.ConfigEnv{})
+		require.Equal(t, []_http.Route{
+			{Method: http.MethodGet, Pattern: "/api/v1/users/{id}"},
+			{Method: http.MethodGet, Pattern: "/healthz"},
+			{Method: http.MethodGet, Pattern: "/readyz"},
+		}, app.Routes())
+
+		// every request of the app gets an ID
+		w := get(app.httpRouter, "/api/v1/users/1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotEmpty(t, w.Header().Get(_http.RequestIDHeader))
+
+		w = get(app.adminRoutes(), AdminRoutesRoute)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"pattern": "/healthz"`)
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		router := NewRouter()
+		router.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+
+		var reported *errors.PanicError
+		app, err := NewApp(
+			WithRouter(router),
+			WithPanicHook(func(ctx context.Context, err *errors.PanicError) {
+				reported = err
+			}),
+		)
+		require.NoError(t, err)
+
+		w := get(app.httpRouter, "/panic")
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "boom", reported.Value)
+	})
+}
+
+func TestRouterLogger(t *testing.T) {
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	router := NewRouter()
+	router.Get("/ping", func(w http.ResponseWriter, r *http.Request) {})
+
+	app, err := NewApp(
+		WithLogger(log),
+		WithRouter(router),
+		WithHttpServerOptions(_http.WithListener(listener)),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, app.Start(context.Background()))
+	<-app.Ready()
+	require.NoError(t, app.Stop(context.Background()))
+
+	// the http server logs with the app logger
+	require.Contains(t, buf.String(), `"msg":"starting server"`)
+}
@@ -0,0 +1,58 @@
+package gocherry
+
+// ServiceState describes the lifecycle state of a registered service or closer.
+type ServiceState string
+
+const (
+	StateRegistered ServiceState = "registered"
+	StateWaiting    ServiceState = "waiting"
+	StateStarting   ServiceState = "starting"
+	StateRunning    ServiceState = "running"
+	StateRestarting ServiceState = "restarting"
+	StateFailed     ServiceState = "failed"
+	StateStopped    ServiceState = "stopped"
+	StateClosed     ServiceState = "closed"
+)
+
+// ServiceInfo describes a service or closer registered in the App.
+type ServiceInfo struct {
+	Name      string       `json:"name" yaml:"name"`
+	Type      string       `json:"type" yaml:"type"`
+	State     ServiceState `json:"state" yaml:"state"`
+	Restarts  int          `json:"restarts" yaml:"restarts"`
+	DependsOn []string     `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+}
+
+// Services lists registered services and closers with their current state.
+func (app *App) Services() []ServiceInfo {
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	services := make([]ServiceInfo, 0, len(app.components))
+
+	for _, comp := range app.components {
+
+		_type := "closer"
+		if comp.service != nil {
+			_type = "service"
+		}
+
+		info := ServiceInfo{
+			Name:     comp.name,
+			Type:     _type,
+			State:    comp.getState(),
+			Restarts: int(comp.restarts.Load()),
+		}
+
+		for _, dep := range comp.deps {
+			if found := lookupComponent(app.components, dep); found != nil {
+				info.DependsOn = append(info.DependsOn, found.name)
+			}
+		}
+
+		services = append(services, info)
+	}
+
+	return services
+}
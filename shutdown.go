@@ -0,0 +1,272 @@
+package gocherry
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vishenosik/gocherry/pkg/errors"
+	"github.com/vishenosik/gocherry/pkg/logs"
+)
+
+const (
+	defaultServiceStopTimeout = 10 * time.Second
+	// forceStopGrace is the time a service has to force stop after its stop timeout
+	forceStopGrace = time.Second
+)
+
+// Phase is the shutdown phase a service is stopped in.
+// Phases run one after another, services of the same phase are stopped in parallel
+// unless they depend on each other.
+type Phase uint8
+
+const (
+	// PhaseListeners stops servers accepting requests. It's the default phase of services.
+	PhaseListeners Phase = iota
+	// PhaseWorkers stops background workers, e.g. the worker pool.
+	PhaseWorkers
+	// PhaseClosers closes closers.
+	PhaseClosers
+)
+
+func (phase Phase) String() string {
+	switch phase {
+	case PhaseListeners:
+		return "listeners"
+	case PhaseWorkers:
+		return "workers"
+	case PhaseClosers:
+		return "closers"
+	default:
+		return "undefined"
+	}
+}
+
+// InPhase sets the shutdown phase of the service.
+func InPhase(phase Phase) ServiceOption {
+	return func(c *component) {
+		c.phase = &phase
+	}
+}
+
+// StopTimeout sets the time the service has to stop gracefully,
+// the service is expected to force stop once its context is done.
+func StopTimeout(timeout time.Duration) ServiceOption {
+	return func(c *component) {
+		if timeout > 0 {
+			c.stopTimeout = timeout
+		}
+	}
+}
+
+// servingSetter is implemented by servers reporting their serving status to clients, e.g. grpc health.
+type servingSetter interface {
+	SetServingStatus(serving bool)
+}
+
+func (c *component) stopPhase() Phase {
+	switch {
+	case c.phase != nil:
+		return *c.phase
+	case c.service == nil:
+		return PhaseClosers
+	}
+	if _, ok := c.service.(*Pool); ok {
+		return PhaseWorkers
+	}
+	return PhaseListeners
+}
+
+// drain marks the app not ready and waits for load balancers to stop sending traffic.
+func (app *App) drain(ctx context.Context, components []*component) {
+
+	app.health.SetReady(false)
+
+	for _, comp := range components {
+		if setter, ok := comp.value.(servingSetter); ok {
+			setter.SetServingStatus(false)
+		}
+	}
+
+	if app.drainDelay <= 0 {
+		return
+	}
+
+	timeStart := time.Now()
+	app.Log.Info("draining app", slog.String("delay", app.drainDelay.String()))
+
+	select {
+	case <-time.After(app.drainDelay):
+	case <-ctx.Done():
+		app.Log.Warn("draining interrupted", logs.Error(ctx.Err()))
+	}
+
+	app.Log.Info("app drained", logs.Took(timeStart))
+}
+
+// shutdown stops components phase by phase, see Phase.
+func (app *App) shutdown(ctx context.Context, order []*component, deps map[*component][]*component) error {
+
+	result := new(errors.MultiError)
+	phases := stopPhases(order, deps)
+
+	for _, phase := range []Phase{PhaseListeners, PhaseWorkers, PhaseClosers} {
+		components := slices.DeleteFunc(slices.Clone(order), func(comp *component) bool {
+			return phases[comp] != phase
+		})
+		result.Append(app.shutdownPhase(ctx, phase, components, deps))
+	}
+
+	return result.ErrorOrNil()
+}
+
+// stopPhases returns phases components are stopped in. A component is stopped in the phase
+// of its dependents if it's a later one, e.g. a listener a worker depends on is stopped with workers,
+// so dependents are always stopped first. Components are expected to be sorted by resolve.
+func stopPhases(order []*component, deps map[*component][]*component) map[*component]Phase {
+
+	phases := make(map[*component]Phase, len(order))
+	for _, comp := range order {
+		phases[comp] = comp.stopPhase()
+	}
+
+	// dependents follow their dependencies, so they are handled first
+	for i := len(order) - 1; i >= 0; i-- {
+		comp := order[i]
+		for _, dep := range deps[comp] {
+			if phases[dep] < phases[comp] {
+				phases[dep] = phases[comp]
+			}
+		}
+	}
+
+	return phases
+}
+
+// abortStart stops components after a start hook failed, so resources they hold are released,
+// e.g. connections opened by modules. Services aren't started yet and stop hooks aren't run.
+func (app *App) abortStart(ctx context.Context, order []*component, deps map[*component][]*component) {
+
+	app.markStopping()
+
+	if err := app.shutdown(context.WithoutCancel(ctx), order, deps); err != nil {
+		app.Log.Error("failed to stop app after failed start", logs.Error(err))
+	}
+}
+
+// shutdownPhase stops components of the phase in the reverse order of dependencies,
+// components of the same dependency level are stopped in parallel.
+func (app *App) shutdownPhase(
+	ctx context.Context,
+	phase Phase,
+	components []*component,
+	deps map[*component][]*component,
+) error {
+
+	result := new(errors.MultiError)
+
+	levels := dependencyLevels(components, deps)
+	if len(levels) == 0 {
+		return nil
+	}
+
+	log := app.Log.With(slog.String("phase", phase.String()))
+	timeStart := time.Now()
+	log.Info("shutdown phase started")
+
+	for i := len(levels) - 1; i >= 0; i-- {
+
+		var (
+			wg sync.WaitGroup
+			mu sync.Mutex
+		)
+
+		for _, comp := range levels[i] {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := app.stopComponent(ctx, comp, log); err != nil {
+					mu.Lock()
+					result.Append(err)
+					mu.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+
+	log.Info("shutdown phase finished", logs.Took(timeStart))
+
+	return result.ErrorOrNil()
+}
+
+func (app *App) stopComponent(ctx context.Context, comp *component, log *slog.Logger) error {
+
+	log = log.With(slog.String("service", comp.name))
+
+	timeout := comp.stopTimeout
+	if timeout <= 0 {
+		timeout = app.serviceStopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stopped := make(chan error, 1)
+
+	go func() {
+		if comp.service != nil {
+			stopped <- comp.service.Stop(ctx)
+			comp.setState(StateStopped)
+			return
+		}
+		stopped <- comp.closer.Close(ctx)
+		comp.setState(StateClosed)
+	}()
+
+	select {
+	case err := <-stopped:
+		return errors.Wrapf(err, "failed to stop %s", comp.name)
+	case <-ctx.Done():
+	}
+
+	log.Warn("service stop timeout exceeded, forcing stop",
+		slog.String("timeout", timeout.String()),
+	)
+
+	select {
+	case err := <-stopped:
+		return errors.Wrapf(err, "failed to stop %s", comp.name)
+	case <-time.After(forceStopGrace):
+		log.Error("service is not stopped")
+		return errors.Wrapf(ctx.Err(), "failed to stop %s", comp.name)
+	}
+}
+
+// dependencyLevels groups components so that every component is placed after its dependencies.
+// Components are expected to be sorted by resolve.
+func dependencyLevels(components []*component, deps map[*component][]*component) [][]*component {
+
+	levels := make([][]*component, 0)
+	level := make(map[*component]int, len(components))
+
+	for _, comp := range components {
+		lvl := 0
+		for _, dep := range deps[comp] {
+			if depLevel, ok := level[dep]; ok && depLevel+1 > lvl {
+				lvl = depLevel + 1
+			}
+		}
+		level[comp] = lvl
+
+		for len(levels) <= lvl {
+			levels = append(levels, nil)
+		}
+		levels[lvl] = append(levels[lvl], comp)
+	}
+
+	return levels
+}
@@ -0,0 +1,173 @@
+package gocherry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/retry"
+)
+
+const (
+	defaultRestartBase = time.Second
+	defaultRestartMax  = 30 * time.Second
+)
+
+type restartMode uint8
+
+const (
+	restartNever restartMode = iota
+	restartAlways
+	restartOnFailure
+)
+
+func (mode restartMode) String() string {
+	switch mode {
+	case restartAlways:
+		return "always"
+	case restartOnFailure:
+		return "on-failure"
+	default:
+		return "never"
+	}
+}
+
+type restartPolicy struct {
+	mode        restartMode
+	maxRestarts int
+	base        time.Duration
+	max         time.Duration
+}
+
+func defaultRestartPolicy() restartPolicy {
+	return restartPolicy{
+		mode: restartNever,
+		base: defaultRestartBase,
+		max:  defaultRestartMax,
+	}
+}
+
+// shouldRestart reports whether the service exited with err must be started again.
+func (policy restartPolicy) shouldRestart(err error, restarts int) bool {
+	switch policy.mode {
+	case restartAlways:
+		return true
+	case restartOnFailure:
+		return err != nil && (policy.maxRestarts <= 0 || restarts < policy.maxRestarts)
+	default:
+		return false
+	}
+}
+
+// RestartNever doesn't restart the service once its Start returns. It's the default policy.
+func RestartNever() ServiceOption {
+	return func(c *component) {
+		c.restart.mode = restartNever
+	}
+}
+
+// RestartAlways restarts the service every time its Start returns or panics while the app is running.
+func RestartAlways() ServiceOption {
+	return func(c *component) {
+		c.restart.mode = restartAlways
+	}
+}
+
+// RestartOnFailure restarts the service when its Start returns an error or panics.
+// The service is considered failed permanently after maxRestarts restarts, zero means no limit.
+func RestartOnFailure(maxRestarts int) ServiceOption {
+	return func(c *component) {
+		c.restart.mode = restartOnFailure
+		c.restart.maxRestarts = maxRestarts
+	}
+}
+
+// RestartBackoff sets the fibonacci backoff used between restarts of the service.
+func RestartBackoff(base, max time.Duration) ServiceOption {
+	return func(c *component) {
+		c.restart.base = base
+		c.restart.max = max
+	}
+}
+
+// supervise runs the service and restarts it following its restart policy.
+// It returns the error the service stays failed with.
+func (app *App) supervise(ctx context.Context, comp *component, log *slog.Logger) error {
+
+	backoff := retry.NewFibonacci(comp.restart.base, comp.restart.max)
+
+	for restarts := 0; ; restarts++ {
+
+		comp.setState(StateStarting)
+
+		// services are started once their Start is called unless they report it themselves
+		attempt := make(chan struct{})
+		if notifier, ok := comp.service.(StartNotifier); ok {
+			go func() {
+				select {
+				case <-notifier.Started():
+					comp.setReady()
+				case <-attempt:
+				}
+			}()
+		} else {
+			comp.setReady()
+		}
+
+		err := runService(ctx, comp.service)
+		close(attempt)
+		if err == nil {
+			comp.setExited()
+		}
+
+		if app.isStopping() || ctx.Err() != nil {
+			return nil
+		}
+
+		if !comp.restart.shouldRestart(err, restarts) {
+			if err != nil && comp.restart.mode != restartNever {
+				log.Error("service failed permanently",
+					slog.Int("restarts", restarts),
+					logs.Error(err),
+				)
+			}
+			return err
+		}
+
+		delay, _ := backoff.Next()
+
+		comp.setState(StateRestarting)
+		comp.restarts.Add(1)
+
+		attrs := []any{
+			slog.String("policy", comp.restart.mode.String()),
+			slog.Int("attempt", restarts+1),
+			slog.String("retry_in", delay.String()),
+		}
+		if err != nil {
+			attrs = append(attrs, logs.Error(err))
+		}
+		log.Warn("restarting service", attrs...)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil
+		case <-app.stopping:
+			return nil
+		}
+	}
+}
+
+// runService calls Service.Start turning a panic into an error.
+func runService(ctx context.Context, service Service) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("service panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return service.Start(ctx)
+}
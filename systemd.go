@@ -0,0 +1,68 @@
+package gocherry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/vishenosik/gocherry/pkg/logs"
+	"github.com/vishenosik/gocherry/pkg/systemd"
+)
+
+// WithSystemdNotify reports the app state to systemd, so it's run by Type=notify units:
+// READY=1 is sent once every service is started, STOPPING=1 once the app begins to stop.
+// When the unit sets WatchdogSec=, WATCHDOG=1 is sent periodically while liveness checks pass.
+// The option does nothing unless the app is started by systemd.
+func WithSystemdNotify() AppOption {
+	return func(app *App) {
+		app.notifier = systemd.NewNotifier()
+		if app.notifier == nil {
+			app.Log.Debug("systemd notify socket is not set")
+		}
+	}
+}
+
+// notify sends the states to systemd if WithSystemdNotify is set.
+func (app *App) notify(states ...string) {
+	if err := app.notifier.Notify(states...); err != nil {
+		app.Log.Warn("failed to notify systemd", logs.Error(err))
+	}
+}
+
+// watchdog pings the systemd watchdog until the app is stopping.
+func (app *App) watchdog() {
+
+	if app.notifier == nil {
+		return
+	}
+
+	interval, ok := systemd.WatchdogInterval()
+	if !ok {
+		return
+	}
+
+	// the ping period is half of the watchdog interval, so a slow check doesn't trigger it
+	period := interval / 2
+
+	app.Log.Info("systemd watchdog is enabled", slog.Duration("interval", interval))
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.stopping:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), period)
+			report := app.health.Live(ctx)
+			cancel()
+
+			if !report.Up() {
+				app.Log.Warn("liveness checks failed, systemd watchdog is not pinged")
+				continue
+			}
+			app.notify(systemd.Watchdog)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package gocherry
 import (
 	"context"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 	"github.com/vishenosik/concurrency"
+	"github.com/vishenosik/gocherry/pkg/config"
 	"github.com/vishenosik/gocherry/pkg/logs"
 )
 
@@ -15,10 +18,34 @@ type PoolTask struct {
 	Priority int
 }
 
+type PoolConfigEnv struct {
+	MinWorkers int32 `env:"POOL_MIN_WORKERS" env-default:"3" desc:"Minimum number of worker pool workers"`
+	MaxWorkers int32 `env:"POOL_MAX_WORKERS" env-default:"256" desc:"Maximum number of worker pool workers"`
+}
+
+func (PoolConfigEnv) Desc() string {
+	return "worker pool settings"
+}
+
+// Validate rejects worker limits the pool can't run with.
+func (conf PoolConfigEnv) Validate() error {
+	if conf.MinWorkers < 1 {
+		return errors.New("pool min workers must be positive")
+	}
+	if conf.MaxWorkers < conf.MinWorkers {
+		return errors.New("pool max workers can't be less than min workers")
+	}
+	return nil
+}
+
 type Pool struct {
 	log     *slog.Logger
 	pool    *concurrency.Pool
 	subChan <-chan PoolTask
+	running atomic.Bool
+	// stopped is closed by Stop, so Start returns
+	stopped  chan struct{}
+	stopOnce sync.Once
 }
 
 func NewPool(subscriptions ...chan PoolTask) (*Pool, error) {
@@ -32,17 +59,30 @@ func NewPoolContext(ctx context.Context, subscriptions ...chan PoolTask) (*Pool,
 
 	log := logs.SetupLogger().With(logs.AppComponent("worker pool"))
 
+	envConf := PoolConfigEnv{MinWorkers: 3, MaxWorkers: 256}
+	if err := config.ReadConfigEnv(&envConf); err != nil {
+		log.Warn("init worker pool: failed to read config", logs.Error(err))
+	}
+	if err := envConf.Validate(); err != nil {
+		return nil, errors.Wrap(err, "failed to validate worker pool config")
+	}
+
 	pool := &Pool{
-		log:     log,
-		pool:    concurrency.NewWorkerPoolContext(ctx, concurrency.WithWorkersControl(3, 256, 3)),
+		log: log,
+		pool: concurrency.NewWorkerPoolContext(ctx,
+			concurrency.WithWorkersControl(envConf.MinWorkers, envConf.MaxWorkers, envConf.MinWorkers),
+		),
 		subChan: concurrency.MergeChannels(ctx, uint16(1024), subscriptions...),
+		stopped: make(chan struct{}),
 	}
 
 	return pool, nil
 }
 
+// Start starts workers and blocks until ctx is done or the pool is stopped.
 func (p *Pool) Start(ctx context.Context) error {
 	p.pool.Start(ctx)
+	p.running.Store(true)
 
 	metrics := p.pool.GetMetrics()
 
@@ -77,11 +117,56 @@ func (p *Pool) Start(ctx context.Context) error {
 		}
 		p.log.Warn("subs exited")
 	}()
+
+	select {
+	case <-ctx.Done():
+	case <-p.stopped:
+	}
 	return nil
 }
 
 func (p *Pool) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stopped) })
+	p.running.Store(false)
 	p.pool.Stop(ctx)
 	p.log.Info("pool stopped")
 	return nil
 }
+
+// ReloadConfig implements config.Reloadable, worker limits are applied without restart.
+func (p *Pool) ReloadConfig() any {
+	return PoolConfigEnv{}
+}
+
+// Reload applies worker limits of the PoolConfigEnv.
+func (p *Pool) Reload(conf any) error {
+	envConf, ok := conf.(PoolConfigEnv)
+	if !ok {
+		return errors.Errorf("unexpected worker pool config type %T", conf)
+	}
+
+	// the pool ignores a min above the current max and a max below the current min,
+	// so limits are set in the order keeping min <= max
+	if envConf.MinWorkers > p.pool.GetMetrics().WorkersMax {
+		p.pool.SetMaxWorkers(envConf.MaxWorkers)
+		p.pool.SetMinWorkers(envConf.MinWorkers)
+	} else {
+		p.pool.SetMinWorkers(envConf.MinWorkers)
+		p.pool.SetMaxWorkers(envConf.MaxWorkers)
+	}
+
+	metrics := p.pool.GetMetrics()
+	p.log.Info("pool limits reloaded",
+		slog.Int("workers_max", int(metrics.WorkersMax)),
+		slog.Int("workers_min", int(metrics.WorkersMin)),
+	)
+	return nil
+}
+
+// CheckHealth reports an error unless the pool is started.
+func (p *Pool) CheckHealth(_ context.Context) error {
+	if !p.running.Load() {
+		return errors.New("worker pool is not running")
+	}
+	return nil
+}